@@ -0,0 +1,49 @@
+package Audit
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+)
+
+// Auditor This is the interface implemented by every audit module
+type Auditor interface {
+	// Name returns the name the audit is selected with on the command line
+	Name() string
+	// Description returns a one line description of the audit for the help text
+	Description() string
+	// Scopes returns the OAuth scopes the audit needs from the authenticated user
+	Scopes() []string
+	// Run executes the audit and writes its reports to the sink
+	Run(ctx context.Context, clients *Clients, sink Report.Sink) error
+}
+
+// Delegator This is the interface implemented by audits that impersonate users with the delegation key
+type Delegator interface {
+	// DelegatedScopes returns the OAuth scopes requested when impersonating a user
+	DelegatedScopes() []string
+}
+
+// NeedsDelegationKey returns true if one of the audits impersonates users
+func NeedsDelegationKey(auditors ...Auditor) bool {
+	for _, auditor := range auditors {
+		if _, ok := auditor.(Delegator); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes returns the union of the scopes required by the audits
+func Scopes(auditors ...Auditor) []string {
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, auditor := range auditors {
+		for _, scope := range auditor.Scopes() {
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
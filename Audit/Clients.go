@@ -0,0 +1,172 @@
+package Audit
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	directory "google.golang.org/api/admin/directory/v1"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Clients This is the struct that holds the Google API wrappers shared by the audits of a run
+type Clients struct {
+	// HTTPClient is the client authenticated as the user running the tool
+	HTTPClient *http.Client
+	// DelegationKey is the service account key used to impersonate users, nil when not provided
+	DelegationKey []byte
+	// Concurrency is the number of entities an audit processes at the same time
+	Concurrency int
+	// Checkpoint records the progress of the audits, nil when the progress is not recorded
+	Checkpoint *Checkpoint.Checkpoint
+	// Errors collects the errors of the entities skipped by the audits
+	Errors *ErrorLog
+	// Customer is the customer of the Directory API requests, empty keeps "my_customer"
+	Customer string
+	// Domains is the allowlist of the domains of the organization, empty lets OrganizationDomains list the verified domains of the customer
+	Domains Domains
+	// OrgUnit is the organizational unit whose subtree the user audits are restricted to, empty covers every user
+	OrgUnit string
+	// InactiveDays and NewAccountDays are the thresholds of the inactive users audit, zero values keep its defaults
+	InactiveDays   []int
+	NewAccountDays int
+	// DirectoryConcurrency and DriveConcurrency override the concurrency of the wrappers when they are not zero
+	DirectoryConcurrency int
+	DriveConcurrency     int
+	// Retry overrides the fields it sets in the retry policy of every wrapper, nil keeps the defaults
+	Retry *GoogleAPI.RetryOverride
+	// Fields are the field masks of the calls of every wrapper, the zero value sends the minimal masks
+	Fields GoogleAPI.FieldMasks
+
+	ctx                      context.Context
+	directoryOnce            sync.Once
+	directoryAPI             *GoogleAPI.DirectoryAPI
+	driveOnce                sync.Once
+	driveAPI                 *GoogleAPI.DriveAPI
+	cloudResourceManagerOnce sync.Once
+	cloudResourceManagerAPI  *GoogleAPI.CloudResourceManagerAPI
+	iamOnce                  sync.Once
+	iamAPI                   *GoogleAPI.IamAPI
+	groupsSettingsOnce       sync.Once
+	groupsSettingsAPI        *GoogleAPI.GroupsSettingsAPI
+	domainsOnce              sync.Once
+	organizationDomains      Domains
+}
+
+// NewClients returns a new Clients for the authenticated client
+func NewClients(client *http.Client, delegationKey []byte, ctx context.Context) *Clients {
+	return &Clients{HTTPClient: client, DelegationKey: delegationKey, Concurrency: 100, Errors: NewErrorLog(), ctx: ctx}
+}
+
+// Directory returns the shared DirectoryAPI
+func (receiver *Clients) Directory() *GoogleAPI.DirectoryAPI {
+	receiver.directoryOnce.Do(func() {
+		receiver.directoryAPI = GoogleAPI.NewDirectoryAPI(receiver.HTTPClient, 2, receiver.ctx)
+		receiver.directoryAPI.Retry.Override(receiver.Retry)
+		receiver.directoryAPI.Fields = receiver.Fields
+		if receiver.Customer != "" {
+			receiver.directoryAPI.Customer = receiver.Customer
+		}
+		if receiver.DirectoryConcurrency > 0 {
+			receiver.directoryAPI.Concurrency = receiver.DirectoryConcurrency
+		}
+	})
+	return receiver.directoryAPI
+}
+
+// Drive returns the shared DriveAPI
+func (receiver *Clients) Drive() *GoogleAPI.DriveAPI {
+	receiver.driveOnce.Do(func() {
+		receiver.driveAPI = receiver.configureDrive(GoogleAPI.NewDriveAPI(receiver.HTTPClient, 3, receiver.ctx))
+	})
+	return receiver.driveAPI
+}
+
+// CloudResourceManager returns the shared CloudResourceManagerAPI
+func (receiver *Clients) CloudResourceManager() *GoogleAPI.CloudResourceManagerAPI {
+	receiver.cloudResourceManagerOnce.Do(func() {
+		receiver.cloudResourceManagerAPI = GoogleAPI.NewCloudResourceManagerAPI(receiver.HTTPClient, 2, receiver.ctx)
+		receiver.cloudResourceManagerAPI.Retry.Override(receiver.Retry)
+		receiver.cloudResourceManagerAPI.Fields = receiver.Fields
+	})
+	return receiver.cloudResourceManagerAPI
+}
+
+// Iam returns the shared IamAPI
+func (receiver *Clients) Iam() *GoogleAPI.IamAPI {
+	receiver.iamOnce.Do(func() {
+		receiver.iamAPI = GoogleAPI.NewIamAPI(receiver.HTTPClient, 60, receiver.ctx)
+		receiver.iamAPI.Retry.Override(receiver.Retry)
+		receiver.iamAPI.Fields = receiver.Fields
+	})
+	return receiver.iamAPI
+}
+
+// GroupsSettings returns the shared GroupsSettingsAPI
+func (receiver *Clients) GroupsSettings() *GoogleAPI.GroupsSettingsAPI {
+	receiver.groupsSettingsOnce.Do(func() {
+		receiver.groupsSettingsAPI = GoogleAPI.NewGroupsSettingsAPI(receiver.HTTPClient, 2, receiver.ctx)
+		receiver.groupsSettingsAPI.Retry.Override(receiver.Retry)
+		receiver.groupsSettingsAPI.Fields = receiver.Fields
+	})
+	return receiver.groupsSettingsAPI
+}
+
+// OrganizationDomains returns the configured Domains, or the verified domains and domain aliases of the customer when none is configured.
+// It returns empty Domains when the domains cannot be listed, the audits then use the domains of the listed entities.
+func (receiver *Clients) OrganizationDomains() Domains {
+	receiver.domainsOnce.Do(func() {
+		if len(receiver.Domains) > 0 {
+			receiver.organizationDomains = receiver.Domains
+			return
+		}
+		domains, err := receiver.Directory().GetVerifiedDomains()
+		if err != nil {
+			log.Printf("Unable to list the verified domains of the customer: %v", err)
+			receiver.organizationDomains = Domains{}
+			return
+		}
+		receiver.organizationDomains = NewDomains(domains...)
+		log.Printf("Verified domains of the customer: %s", strings.Join(receiver.organizationDomains.List(), ", "))
+	})
+	return receiver.organizationDomains
+}
+
+// InOrgUnit returns true when the organizational unit path is in the OrgUnit subtree, always when OrgUnit is empty
+func (receiver *Clients) InOrgUnit(orgUnitPath string) bool {
+	return receiver.OrgUnit == "" || GoogleAPI.InOrgUnit(orgUnitPath, receiver.OrgUnit)
+}
+
+// FilterUsers returns the users placed in the OrgUnit subtree, every user when OrgUnit is empty
+func (receiver *Clients) FilterUsers(users []*directory.User) []*directory.User {
+	if receiver.OrgUnit == "" {
+		return users
+	}
+	var filtered []*directory.User
+	for _, user := range users {
+		if receiver.InOrgUnit(user.OrgUnitPath) {
+			filtered = append(filtered, user)
+		}
+	}
+	return filtered
+}
+
+// DelegatedDrive returns a new DriveAPI impersonating the given user with the delegation key
+func (receiver *Clients) DelegatedDrive(subjectEmail string, scopes []string) *GoogleAPI.DriveAPI {
+	jwt := GoogleAPI.GetJWTClient(subjectEmail, receiver.DelegationKey, scopes, receiver.ctx)
+	driveAPI := receiver.configureDrive(GoogleAPI.NewDriveAPI(jwt, 3, receiver.ctx))
+	driveAPI.Subject = subjectEmail
+	return driveAPI
+}
+
+// configureDrive applies the retry policy, the concurrency and the field masks of the clients to a DriveAPI
+func (receiver *Clients) configureDrive(driveAPI *GoogleAPI.DriveAPI) *GoogleAPI.DriveAPI {
+	driveAPI.Retry.Override(receiver.Retry)
+	driveAPI.Fields = receiver.Fields
+	if receiver.DriveConcurrency > 0 {
+		driveAPI.Concurrency = receiver.DriveConcurrency
+	}
+	return driveAPI
+}
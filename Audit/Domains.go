@@ -0,0 +1,67 @@
+package Audit
+
+import (
+	directory "google.golang.org/api/admin/directory/v1"
+	"sort"
+	"strings"
+)
+
+// Classifications of the members of a group against the domains of the organization
+const (
+	MemberInternal      = "internal"       // A user or group of one of the domains
+	MemberExternalUser  = "external_user"  // A user of another domain, like a consumer account
+	MemberExternalGroup = "external_group" // A group of another domain
+	MemberCustomer      = "customer"       // Every user of the customer, added as a CUSTOMER member
+)
+
+// Domains This is the allowlist of the domains of the organization, the members of other domains are external
+type Domains map[string]bool
+
+// NewDomains returns the allowlist of the given domains, the domains are compared case insensitively
+func NewDomains(domains ...string) Domains {
+	allowlist := make(Domains, len(domains))
+	for _, domain := range domains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			allowlist[domain] = true
+		}
+	}
+	return allowlist
+}
+
+// List returns the sorted domains of the allowlist
+func (receiver Domains) List() []string {
+	domains := make([]string, 0, len(receiver))
+	for domain := range receiver {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// Domain returns the lowercase domain of an email, empty when it has none
+func Domain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// IsInternal returns true when the email belongs to one of the domains
+func (receiver Domains) IsInternal(email string) bool {
+	return receiver[Domain(email)]
+}
+
+// Classify returns the classification of a group member: MemberInternal, MemberExternalUser, MemberExternalGroup or MemberCustomer
+func (receiver Domains) Classify(member *directory.Member) string {
+	switch {
+	case member.Type == "CUSTOMER":
+		return MemberCustomer
+	case receiver.IsInternal(member.Email):
+		return MemberInternal
+	case member.Type == "GROUP":
+		return MemberExternalGroup
+	default:
+		return MemberExternalUser
+	}
+}
@@ -0,0 +1,27 @@
+package Audit_test
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	directory "google.golang.org/api/admin/directory/v1"
+	"testing"
+)
+
+func TestDomainsClassify(t *testing.T) {
+	domains := Audit.NewDomains("Example.com", " example.org ")
+	tests := []struct {
+		member *directory.Member
+		want   string
+	}{
+		{&directory.Member{Email: "alice@EXAMPLE.com", Type: "USER"}, Audit.MemberInternal},
+		{&directory.Member{Email: "team@example.org", Type: "GROUP"}, Audit.MemberInternal},
+		{&directory.Member{Email: "partner@other.org", Type: "USER"}, Audit.MemberExternalUser},
+		{&directory.Member{Email: "list@googlegroups.com", Type: "GROUP"}, Audit.MemberExternalGroup},
+		{&directory.Member{Id: "C01abc", Type: "CUSTOMER"}, Audit.MemberCustomer},
+		{&directory.Member{Email: "alice@sub.example.com", Type: "USER"}, Audit.MemberExternalUser},
+	}
+	for _, test := range tests {
+		if got := domains.Classify(test.member); got != test.want {
+			t.Errorf("Classify(%s %s) = %s, want %s", test.member.Type, test.member.Email, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package Audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CallError This is the error of a call to a Google API, it names the API method in the error log
+type CallError struct {
+	API string
+	Err error
+}
+
+// Error returns the error of the call
+func (receiver *CallError) Error() string {
+	return receiver.API + ": " + receiver.Err.Error()
+}
+
+// Unwrap returns the error of the call
+func (receiver *CallError) Unwrap() error {
+	return receiver.Err
+}
+
+// Call wraps the error returned by the API method, nil stays nil
+func Call(api string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CallError{API: api, Err: err}
+}
+
+// EntityError This is the error of a single entity that was skipped instead of aborting the audit
+type EntityError struct {
+	Time       time.Time `json:"time"`
+	Audit      string    `json:"audit"`
+	EntityType string    `json:"entity_type"`
+	EntityId   string    `json:"entity_id"`
+	API        string    `json:"api"`
+	HTTPCode   int       `json:"http_code"`
+	Message    string    `json:"message"`
+}
+
+// ErrorLog This is the collection of the entity errors of a run, it is safe for concurrent use
+type ErrorLog struct {
+	mu       sync.Mutex
+	errors   []*EntityError
+	entities int
+}
+
+// NewErrorLog returns an empty ErrorLog
+func NewErrorLog() *ErrorLog {
+	return &ErrorLog{}
+}
+
+// Add records the error of an entity, the API and the HTTP code are taken from the CallError and the Google API error it wraps
+func (receiver *ErrorLog) Add(audit, entityType, entityId string, err error) {
+	entityError := &EntityError{
+		Time:       time.Now(),
+		Audit:      audit,
+		EntityType: entityType,
+		EntityId:   entityId,
+		HTTPCode:   HTTPCode(err),
+		Message:    err.Error(),
+	}
+	var callErr *CallError
+	if errors.As(err, &callErr) {
+		entityError.API = callErr.API
+		entityError.Message = callErr.Err.Error()
+	}
+	log.Printf("%s %s %s skipped: %s", audit, entityType, entityId, err.Error())
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.errors = append(receiver.errors, entityError)
+}
+
+// Processed adds the number of entities processed by an audit, the error rate is relative to it
+func (receiver *ErrorLog) Processed(count int) {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.entities += count
+}
+
+// Restore adds the errors and the number of processed entities of an audit completed by a previous run, without logging them again
+func (receiver *ErrorLog) Restore(entityErrors []*EntityError, processed int) {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.errors = append(receiver.errors, entityErrors...)
+	receiver.entities += processed
+}
+
+// Counts returns the number of recorded errors and of processed entities
+func (receiver *ErrorLog) Counts() (errorCount, processed int) {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	return len(receiver.errors), receiver.entities
+}
+
+// Errors returns the recorded errors
+func (receiver *ErrorLog) Errors() []*EntityError {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	return append([]*EntityError(nil), receiver.errors...)
+}
+
+// Rate returns the number of errors divided by the number of processed entities
+func (receiver *ErrorLog) Rate() float64 {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if receiver.entities == 0 {
+		return 0
+	}
+	return float64(len(receiver.errors)) / float64(receiver.entities)
+}
+
+// Write writes the errors to errors.csv and errors.jsonl in the directory
+func (receiver *ErrorLog) Write(path string) error {
+	entityErrors := receiver.Errors()
+
+	// Write the csv
+	csvFile, err := os.Create(filepath.Join(path, "errors.csv"))
+	if err != nil {
+		return err
+	}
+	defer csvFile.Close()
+	writer := csv.NewWriter(csvFile)
+	_ = writer.Write([]string{"TIME", "AUDIT", "ENTITY_TYPE", "ENTITY_ID", "API", "HTTP_CODE", "MESSAGE"})
+	for _, entityError := range entityErrors {
+		_ = writer.Write([]string{
+			entityError.Time.Format(time.RFC3339),
+			entityError.Audit,
+			entityError.EntityType,
+			entityError.EntityId,
+			entityError.API,
+			strconv.Itoa(entityError.HTTPCode),
+			entityError.Message})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	// Write the json lines
+	jsonFile, err := os.Create(filepath.Join(path, "errors.jsonl"))
+	if err != nil {
+		return err
+	}
+	defer jsonFile.Close()
+	encoder := json.NewEncoder(jsonFile)
+	for _, entityError := range entityErrors {
+		if err := encoder.Encode(entityError); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordFailures records the failed jobs of the results, the jobs cancelled by the context are not entity errors
+func RecordFailures[J, R any](ctx context.Context, errorLog *ErrorLog, audit, entityType string, jobs []J, id func(job J) string, results []WorkerPool.Result[R]) {
+	errorLog.Processed(len(jobs))
+	for _, result := range WorkerPool.Errors(results) {
+		if ctx.Err() != nil && errors.Is(result.Err, ctx.Err()) {
+			continue
+		}
+		errorLog.Add(audit, entityType, id(jobs[result.Index]), result.Err)
+	}
+}
+
+// HTTPCode returns the HTTP status code of a Google API or OAuth2 error, zero for the other errors
+func HTTPCode(err error) int {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	var tokenErr *oauth2.RetrieveError
+	if errors.As(err, &tokenErr) && tokenErr.Response != nil {
+		return tokenErr.Response.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,128 @@
+package Audit_test
+
+import (
+	"context"
+	"errors"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	"google.golang.org/api/googleapi"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordFailures(t *testing.T) {
+	errorLog := Audit.NewErrorLog()
+	jobs := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"}
+	results := WorkerPool.Run(context.Background(), jobs, WorkerPool.Options{Concurrency: 2}, func(ctx context.Context, job string) (string, error) {
+		if job == "b@example.com" {
+			return "", Audit.Call("DirectoryAPI.GetGroupMembers", &googleapi.Error{Code: 404, Message: "Resource Not Found"})
+		}
+		return job, nil
+	})
+
+	Audit.RecordFailures(context.Background(), errorLog, "groups", "group", jobs, func(job string) string { return job }, results)
+
+	entityErrors := errorLog.Errors()
+	if len(entityErrors) != 1 {
+		t.Fatalf("RecordFailures recorded %d errors, want 1", len(entityErrors))
+	}
+	got := entityErrors[0]
+	if got.EntityId != "b@example.com" || got.API != "DirectoryAPI.GetGroupMembers" || got.HTTPCode != 404 || got.EntityType != "group" {
+		t.Errorf("RecordFailures recorded %+v", got)
+	}
+	if rate := errorLog.Rate(); rate != 0.25 {
+		t.Errorf("Rate = %v, want 0.25", rate)
+	}
+}
+
+func TestRecordFailuresIgnoresCancelledJobs(t *testing.T) {
+	errorLog := Audit.NewErrorLog()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	jobs := []string{"a", "b"}
+	results := WorkerPool.Run(ctx, jobs, WorkerPool.Options{Concurrency: 1}, func(ctx context.Context, job string) (string, error) {
+		// The jobs started before the cancellation was seen fail like the wrappers with the error of the context
+		return "", ctx.Err()
+	})
+
+	Audit.RecordFailures(ctx, errorLog, "groups", "group", jobs, func(job string) string { return job }, results)
+	if len(errorLog.Errors()) != 0 {
+		t.Errorf("RecordFailures recorded %d cancelled jobs, want 0", len(errorLog.Errors()))
+	}
+}
+
+func TestErrorLogWrite(t *testing.T) {
+	dir := t.TempDir()
+	errorLog := Audit.NewErrorLog()
+	errorLog.Add("apps-scripts", "user", "suspended@example.com", Audit.Call("DriveAPI.GetFiles", errors.New("unauthorized_client")))
+	if err := errorLog.Write(dir); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	for _, name := range []string{"errors.csv", "errors.jsonl"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile %s: %v", name, err)
+		}
+		if !strings.Contains(string(data), "suspended@example.com") || !strings.Contains(string(data), "DriveAPI.GetFiles") {
+			t.Errorf("%s = %q, want the error of suspended@example.com", name, data)
+		}
+	}
+}
+
+// failingAuditor This is an audit that processes the entities and skips one of them with an error
+type failingAuditor struct {
+	name string
+	runs int
+}
+
+func (receiver *failingAuditor) Name() string        { return receiver.name }
+func (receiver *failingAuditor) Description() string { return "" }
+func (receiver *failingAuditor) Scopes() []string    { return nil }
+func (receiver *failingAuditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	receiver.runs++
+	clients.Errors.Processed(4)
+	clients.Errors.Add(receiver.name, "user", receiver.name+"@example.com", errors.New("forbidden"))
+	return nil
+}
+
+func TestRunRestoresTheErrorsOfCompletedAudits(t *testing.T) {
+	dir := t.TempDir()
+	first, second := &failingAuditor{name: "first"}, &failingAuditor{name: "second"}
+
+	// The first run completes the first audit and is interrupted before the second one
+	clients := Audit.NewClients(nil, nil, context.Background())
+	clients.Checkpoint = Checkpoint.New(dir)
+	if _, err := Audit.Run(context.Background(), []Audit.Auditor{first}, clients, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// The resumed run skips the first audit but still counts its errors
+	checkpoint, err := Checkpoint.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	clients = Audit.NewClients(nil, nil, context.Background())
+	clients.Checkpoint = checkpoint
+	summary, err := Audit.Run(context.Background(), []Audit.Auditor{first, second}, clients, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if first.runs != 1 || second.runs != 1 {
+		t.Errorf("the audits ran %d and %d times, want once each", first.runs, second.runs)
+	}
+	if summary.Errors != 2 || summary.ErrorRate != 0.25 {
+		t.Errorf("summary has %d errors at a rate of %v, want 2 at 0.25", summary.Errors, summary.ErrorRate)
+	}
+	var ids []string
+	for _, entityError := range clients.Errors.Errors() {
+		ids = append(ids, entityError.EntityId)
+	}
+	if strings.Join(ids, ",") != "first@example.com,second@example.com" {
+		t.Errorf("error log holds %v, want the errors of both audits", ids)
+	}
+}
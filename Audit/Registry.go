@@ -0,0 +1,42 @@
+package Audit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// registry holds the audits registered by the audit packages
+var registry = struct {
+	sync.RWMutex
+	auditors map[string]Auditor
+}{auditors: make(map[string]Auditor)}
+
+// Register makes an audit available by its name, it is meant to be called from the init function of the audit package
+func Register(auditor Auditor) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, exists := registry.auditors[auditor.Name()]; exists {
+		panic(fmt.Sprintf("Audit: Register called twice for %s", auditor.Name()))
+	}
+	registry.auditors[auditor.Name()] = auditor
+}
+
+// Get returns the audit registered with the given name or nil
+func Get(name string) Auditor {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.auditors[name]
+}
+
+// All returns every registered audit sorted by name
+func All() []Auditor {
+	registry.RLock()
+	defer registry.RUnlock()
+	var auditors []Auditor
+	for _, auditor := range registry.auditors {
+		auditors = append(auditors, auditor)
+	}
+	sort.Slice(auditors, func(i, j int) bool { return auditors[i].Name() < auditors[j].Name() })
+	return auditors
+}
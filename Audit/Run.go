@@ -0,0 +1,85 @@
+package Audit
+
+import (
+	"context"
+	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"log"
+	"time"
+)
+
+// Run executes the audits one after the other sharing the clients and the sink.
+// Once the context is done the running audit writes what it collected and the remaining audits are not started.
+// The audits completed according to the checkpoint of the clients are skipped, the checkpoint is saved after every audit.
+// The errors of the completed audits are saved with the checkpoint and restored in the error log when they are skipped.
+func Run(ctx context.Context, auditors []Auditor, clients *Clients, sink Report.Sink) (*Summary, error) {
+	summary := &Summary{Started: time.Now()}
+	var failed []string
+	for _, auditor := range auditors {
+		result := &Result{Audit: auditor.Name(), Status: StatusNotStarted}
+		summary.Results = append(summary.Results, result)
+		if ctx.Err() != nil {
+			continue
+		}
+		if clients.Checkpoint.Done(auditor.Name()) {
+			log.Printf("Skipping %s audit, completed by the previous run", auditor.Name())
+			result.Status = StatusCompleted
+			entityErrors, processed, err := Checkpoint.RecordedErrors[*EntityError](clients.Checkpoint, auditor.Name())
+			if err != nil {
+				log.Printf("Unable to restore the errors of %s: %s", auditor.Name(), err.Error())
+			}
+			clients.Errors.Restore(entityErrors, processed)
+			result.Errors = len(entityErrors)
+			continue
+		}
+
+		errorCount, processed := clients.Errors.Counts()
+		timer := time.Now()
+		log.Printf("Starting %s audit...", auditor.Name())
+		err := auditor.Run(ctx, clients, sink)
+		result.Duration = time.Since(timer).Round(time.Millisecond).String()
+		result.Errors = len(clients.Errors.Errors()) - errorCount
+		switch {
+		case err != nil && ctx.Err() != nil:
+			log.Printf("%s audit interrupted: %s", auditor.Name(), err.Error())
+			result.Status = StatusPartial
+			result.Error = err.Error()
+		case err != nil:
+			log.Printf("%s audit failed: %s", auditor.Name(), err.Error())
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			failed = append(failed, auditor.Name())
+		default:
+			log.Printf("%s audit completed in %s", auditor.Name(), result.Duration)
+			result.Status = StatusCompleted
+		}
+
+		// Save the progress of the audit for the next run
+		if result.Status == StatusCompleted {
+			entityErrors := clients.Errors.Errors()[errorCount:]
+			_, total := clients.Errors.Counts()
+			if err = clients.Checkpoint.RecordErrors(auditor.Name(), entityErrors, total-processed); err == nil {
+				err = clients.Checkpoint.MarkDone(auditor.Name())
+			}
+		} else {
+			err = clients.Checkpoint.Save()
+		}
+		if err != nil {
+			log.Printf("Unable to save the checkpoint of %s: %s", auditor.Name(), err.Error())
+		}
+	}
+	summary.Finished = time.Now()
+	summary.Errors = len(clients.Errors.Errors())
+	summary.ErrorRate = clients.Errors.Rate()
+
+	// Record why the run stopped early
+	if err := ctx.Err(); err != nil {
+		summary.Interrupted = true
+		summary.Reason = context.Cause(ctx).Error()
+	}
+	if len(failed) > 0 {
+		return summary, fmt.Errorf("%d of %d audits failed: %v", len(failed), len(auditors), failed)
+	}
+	return summary, nil
+}
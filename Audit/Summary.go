@@ -0,0 +1,89 @@
+package Audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Status values of an audit in the Summary
+const (
+	StatusCompleted  = "completed"   // The audit collected everything
+	StatusPartial    = "partial"     // The audit was interrupted and wrote what it collected so far
+	StatusFailed     = "failed"      // The audit failed on its own
+	StatusNotStarted = "not_started" // The run was interrupted before the audit started
+)
+
+// PartialReportFile This is the name of the file marking the reports of an interrupted run
+const PartialReportFile = "PARTIAL_REPORT.txt"
+
+// Result This is the outcome of one audit of a run
+type Result struct {
+	Audit    string `json:"audit"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	// Errors is the number of entities of the audit skipped because of an error
+	Errors int `json:"errors"`
+}
+
+// Summary This is the outcome of a run written next to the reports
+type Summary struct {
+	Started     time.Time `json:"started"`
+	Finished    time.Time `json:"finished"`
+	Interrupted bool      `json:"interrupted"`
+	// Reason is the cause of the interruption
+	Reason string `json:"reason,omitempty"`
+	// Errors is the number of entities skipped because of an error, listed in errors.csv
+	Errors int `json:"errors"`
+	// ErrorRate is the number of errors divided by the number of entities processed
+	ErrorRate float64   `json:"error_rate"`
+	Results   []*Result `json:"results"`
+}
+
+// Incomplete returns the results of the audits that did not collect everything
+func (receiver *Summary) Incomplete() []*Result {
+	var incomplete []*Result
+	for _, result := range receiver.Results {
+		if result.Status != StatusCompleted {
+			incomplete = append(incomplete, result)
+		}
+	}
+	return incomplete
+}
+
+// Write writes summary.json to the directory and marks the reports as partial when the run was interrupted
+func (receiver *Summary) Write(path string) error {
+	data, err := json.MarshalIndent(receiver, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(path, "summary.json"), data, 0644); err != nil {
+		return err
+	}
+	if !receiver.Interrupted {
+		// Drop the marker left by the interrupted run this run resumed
+		if err := os.Remove(filepath.Join(path, PartialReportFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	// List what was not collected so nobody mistakes the reports for a complete run
+	text := &strings.Builder{}
+	fmt.Fprintf(text, "PARTIAL REPORT: the run was interrupted at %s (%s).\n", receiver.Finished.Format(time.RFC3339), receiver.Reason)
+	fmt.Fprintf(text, "The reports of this directory only hold the data collected before the interruption.\n\nNot collected:\n")
+	for _, result := range receiver.Incomplete() {
+		switch result.Status {
+		case StatusNotStarted:
+			fmt.Fprintf(text, "  %s: not started\n", result.Audit)
+		default:
+			fmt.Fprintf(text, "  %s: %s - %s\n", result.Audit, result.Status, result.Error)
+		}
+	}
+	return os.WriteFile(filepath.Join(path, PartialReportFile), []byte(text.String()), 0644)
+}
@@ -0,0 +1,169 @@
+package Audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TenantSummary This is the outcome of the run of one tenant of a multi-customer run
+type TenantSummary struct {
+	Tenant     string `json:"tenant"`
+	CustomerID string `json:"customer_id"`
+	// Path is the reports folder of the tenant
+	Path string `json:"path"`
+	// Status is completed when every audit of the tenant completed, partial, failed or not_started otherwise
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Summary is the summary of the run of the tenant, nil when its audits did not run
+	Summary *Summary `json:"summary,omitempty"`
+}
+
+// NewTenantSummary returns the outcome of a tenant from the summary of its run, nil when it did not run, and the error that stopped it
+func NewTenantSummary(tenant, customerID, path string, summary *Summary, err error) *TenantSummary {
+	tenantSummary := &TenantSummary{Tenant: tenant, CustomerID: customerID, Path: path, Status: StatusCompleted, Summary: summary}
+	if err != nil {
+		tenantSummary.Error = err.Error()
+	}
+	switch {
+	case summary == nil && err != nil:
+		tenantSummary.Status = StatusFailed
+	case summary == nil:
+		tenantSummary.Status = StatusNotStarted
+	case summary.Interrupted:
+		tenantSummary.Status = StatusPartial
+	case err != nil:
+		tenantSummary.Status = StatusFailed
+	default:
+		for _, result := range summary.Results {
+			if result.Status == StatusFailed {
+				tenantSummary.Status = StatusFailed
+				break
+			}
+		}
+	}
+	return tenantSummary
+}
+
+// CombinedSummary This is the outcome of a multi-customer run, written at the root of the reports next to the folders of the tenants
+type CombinedSummary struct {
+	Started     time.Time `json:"started"`
+	Finished    time.Time `json:"finished"`
+	Interrupted bool      `json:"interrupted"`
+	// Reason is the cause of the interruption
+	Reason  string           `json:"reason,omitempty"`
+	Tenants []*TenantSummary `json:"tenants"`
+}
+
+// Incomplete returns the tenants whose audits did not all complete
+func (receiver *CombinedSummary) Incomplete() []*TenantSummary {
+	var incomplete []*TenantSummary
+	for _, tenant := range receiver.Tenants {
+		if tenant.Status != StatusCompleted {
+			incomplete = append(incomplete, tenant)
+		}
+	}
+	return incomplete
+}
+
+// Write writes summary.json to the directory and marks the reports as partial when the run was interrupted
+func (receiver *CombinedSummary) Write(path string) error {
+	data, err := json.MarshalIndent(receiver, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(path, "summary.json"), data, 0644); err != nil {
+		return err
+	}
+	if !receiver.Interrupted {
+		// Drop the marker left by the interrupted run this run resumed
+		if err := os.Remove(filepath.Join(path, PartialReportFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	// List the tenants that were not fully assessed, their folders list what was not collected
+	text := &strings.Builder{}
+	fmt.Fprintf(text, "PARTIAL REPORT: the run was interrupted at %s (%s).\n", receiver.Finished.Format(time.RFC3339), receiver.Reason)
+	fmt.Fprintf(text, "The reports of the following tenants only hold the data collected before the interruption.\n\nNot collected:\n")
+	for _, tenant := range receiver.Incomplete() {
+		fmt.Fprintf(text, "  %s: %s\n", tenant.Tenant, tenant.Status)
+	}
+	return os.WriteFile(filepath.Join(path, PartialReportFile), []byte(text.String()), 0644)
+}
+
+// WriteReports writes the tenantsSummary report with a row per tenant, the tenantsAudits report with a row per audit of every tenant
+// and the tenantsFindings report rolling up every audit across the tenants
+func (receiver *CombinedSummary) WriteReports(sink Report.Sink) error {
+	var tenantRows, auditRows [][]any
+	for _, tenant := range receiver.Tenants {
+		completed, audits, errorCount, errorRate := 0, 0, 0, 0.0
+		if tenant.Summary != nil {
+			audits, errorCount, errorRate = len(tenant.Summary.Results), tenant.Summary.Errors, tenant.Summary.ErrorRate
+			for _, result := range tenant.Summary.Results {
+				if result.Status == StatusCompleted {
+					completed++
+				}
+				auditRows = append(auditRows, []any{tenant.Tenant, tenant.CustomerID, result.Audit, result.Status, result.Duration, result.Errors, result.Error})
+			}
+		}
+		tenantRows = append(tenantRows, []any{tenant.Tenant, tenant.CustomerID, tenant.Status, completed, audits, errorCount, errorRate, tenant.Path, tenant.Error})
+	}
+	headers := []string{"TENANT", "CUSTOMER_ID", "STATUS", "COMPLETED_AUDITS", "AUDITS", "ERRORS", "ERROR_RATE", "PATH", "ERROR"}
+	if err := Report.WriteAll(sink, "tenantsSummary", headers, tenantRows); err != nil {
+		return err
+	}
+	headers = []string{"TENANT", "CUSTOMER_ID", "AUDIT", "STATUS", "DURATION", "ERRORS", "ERROR"}
+	if err := Report.WriteAll(sink, "tenantsAudits", headers, auditRows); err != nil {
+		return err
+	}
+	return receiver.writeFindings(sink)
+}
+
+// writeFindings writes the tenantsFindings report with a row per audit counting its statuses and errors across the tenants,
+// and listing the tenants where it did not complete and the tenants where it skipped entities with their number of errors
+func (receiver *CombinedSummary) writeFindings(sink Report.Sink) error {
+	type finding struct {
+		statuses               map[string]int
+		errors                 int
+		incomplete, withErrors []string
+	}
+	// Keep the audits in the order of the runs
+	var audits []string
+	findings := make(map[string]*finding)
+	for _, tenant := range receiver.Tenants {
+		if tenant.Summary == nil {
+			continue
+		}
+		for _, result := range tenant.Summary.Results {
+			if findings[result.Audit] == nil {
+				audits = append(audits, result.Audit)
+				findings[result.Audit] = &finding{statuses: make(map[string]int)}
+			}
+			audit := findings[result.Audit]
+			audit.statuses[result.Status]++
+			audit.errors += result.Errors
+			if result.Status != StatusCompleted {
+				audit.incomplete = append(audit.incomplete, fmt.Sprintf("%s: %s", tenant.Tenant, result.Status))
+			}
+			if result.Errors > 0 {
+				audit.withErrors = append(audit.withErrors, fmt.Sprintf("%s: %d", tenant.Tenant, result.Errors))
+			}
+		}
+	}
+
+	var csvRows [][]any
+	for _, name := range audits {
+		audit := findings[name]
+		csvRows = append(csvRows, []any{name, audit.statuses[StatusCompleted], audit.statuses[StatusPartial], audit.statuses[StatusFailed],
+			audit.statuses[StatusNotStarted], audit.errors, audit.incomplete, audit.withErrors})
+	}
+	headers := []string{"AUDIT", "COMPLETED_TENANTS", "PARTIAL_TENANTS", "FAILED_TENANTS", "NOT_STARTED_TENANTS", "ERRORS", "INCOMPLETE_TENANTS", "TENANTS_WITH_ERRORS"}
+	return Report.WriteAll(sink, "tenantsFindings", headers, csvRows)
+}
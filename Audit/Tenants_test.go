@@ -0,0 +1,111 @@
+package Audit_test
+
+import (
+	"errors"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewTenantSummary(t *testing.T) {
+	completed := &Audit.Summary{Results: []*Audit.Result{{Audit: "users", Status: Audit.StatusCompleted}}}
+	failed := &Audit.Summary{Results: []*Audit.Result{{Audit: "users", Status: Audit.StatusCompleted}, {Audit: "groups", Status: Audit.StatusFailed}}}
+	interrupted := &Audit.Summary{Interrupted: true, Results: []*Audit.Result{{Audit: "users", Status: Audit.StatusPartial}}}
+	for _, test := range []struct {
+		summary *Audit.Summary
+		err     error
+		status  string
+	}{
+		{completed, nil, Audit.StatusCompleted},
+		{failed, errors.New("1 of 2 audits failed: [groups]"), Audit.StatusFailed},
+		{interrupted, nil, Audit.StatusPartial},
+		{nil, errors.New("invalid_grant"), Audit.StatusFailed},
+		{nil, nil, Audit.StatusNotStarted},
+	} {
+		if status := Audit.NewTenantSummary("agency", "C0123", "reports/agency", test.summary, test.err).Status; status != test.status {
+			t.Errorf("NewTenantSummary(%+v, %v) status = %s, want %s", test.summary, test.err, status, test.status)
+		}
+	}
+}
+
+func TestCombinedSummaryWrite(t *testing.T) {
+	dir := t.TempDir()
+	combined := &Audit.CombinedSummary{Interrupted: true, Reason: "interrupted by interrupt", Tenants: []*Audit.TenantSummary{
+		Audit.NewTenantSummary("agency", "C0123", filepath.Join(dir, "agency"), &Audit.Summary{Results: []*Audit.Result{{Audit: "users", Status: Audit.StatusCompleted}}}, nil),
+		Audit.NewTenantSummary("bureau", "C0456", filepath.Join(dir, "bureau"), nil, nil),
+	}}
+	if err := combined.Write(dir); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	sink := Report.NewCSVSink(dir)
+	if err := combined.WriteReports(sink); err != nil {
+		t.Fatalf("WriteReports: %v", err)
+	}
+
+	for name, want := range map[string][]string{
+		"summary.json":          {`"tenant": "agency"`, `"customer_id": "C0456"`, `"status": "not_started"`},
+		Audit.PartialReportFile: {"bureau: not_started"},
+		"tenantsSummary.csv":    {"agency,C0123,completed,1,1", "bureau,C0456,not_started,0,0"},
+		"tenantsAudits.csv":     {"agency,C0123,users,completed"},
+	} {
+		data := readFile(t, filepath.Join(dir, name))
+		for _, text := range want {
+			if !strings.Contains(data, text) {
+				t.Errorf("%s = %q, want %s", name, data, text)
+			}
+		}
+	}
+	if strings.Contains(readFile(t, filepath.Join(dir, Audit.PartialReportFile)), "agency") {
+		t.Errorf("%s lists the completed tenant", Audit.PartialReportFile)
+	}
+}
+
+func TestCombinedSummaryWriteFindings(t *testing.T) {
+	dir := t.TempDir()
+	combined := &Audit.CombinedSummary{Tenants: []*Audit.TenantSummary{
+		Audit.NewTenantSummary("agency", "C0123", filepath.Join(dir, "agency"), &Audit.Summary{Errors: 3, Results: []*Audit.Result{
+			{Audit: "users", Status: Audit.StatusCompleted, Errors: 2},
+			{Audit: "groups", Status: Audit.StatusCompleted, Errors: 1},
+		}}, nil),
+		Audit.NewTenantSummary("bureau", "C0456", filepath.Join(dir, "bureau"), &Audit.Summary{Errors: 4, Results: []*Audit.Result{
+			{Audit: "users", Status: Audit.StatusCompleted, Errors: 4},
+			{Audit: "groups", Status: Audit.StatusFailed, Error: "forbidden"},
+		}}, nil),
+	}}
+	if err := combined.WriteReports(Report.NewCSVSink(dir)); err != nil {
+		t.Fatalf("WriteReports: %v", err)
+	}
+
+	data := readFile(t, filepath.Join(dir, "tenantsFindings.csv"))
+	for _, text := range []string{
+		"AUDIT,COMPLETED_TENANTS,PARTIAL_TENANTS,FAILED_TENANTS,NOT_STARTED_TENANTS,ERRORS,INCOMPLETE_TENANTS,TENANTS_WITH_ERRORS",
+		"users,2,0,0,0,6,",
+		"groups,1,0,1,0,1,",
+		"bureau: failed",
+		"agency: 2",
+		"bureau: 4",
+		"agency: 1",
+	} {
+		if !strings.Contains(data, text) {
+			t.Errorf("tenantsFindings.csv = %q, want %s", data, text)
+		}
+	}
+	if lines := strings.Count(strings.TrimSpace(data), "\n"); lines != 2 {
+		t.Errorf("tenantsFindings.csv has %d rows, want a row per audit across the tenants", lines)
+	}
+	if auditsData := readFile(t, filepath.Join(dir, "tenantsAudits.csv")); !strings.Contains(auditsData, "bureau,C0456,users,completed,,4,") {
+		t.Errorf("tenantsAudits.csv = %q, want the errors of every audit", auditsData)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile %s: %v", path, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,330 @@
+package AdminRoles
+
+import (
+	"context"
+	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/iam/v1"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Types of the assignees of a role
+const (
+	AssigneeUser           = "user"
+	AssigneeGroup          = "group"
+	AssigneeServiceAccount = "service_account" // The unique id of a service account found with the IAM API
+	AssigneeUnknown        = "unknown"         // Neither a user, a group nor a service account, like a deleted user or a user missing from the listing
+)
+
+// Auditor This is the audit of the admin roles, their privileges and their assignments
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "admin-roles"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Admin roles, their privileges and assignees, flagging the super admin count and risky custom roles"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryRolemanagementReadonlyScope,
+		directory.AdminDirectoryUserReadonlyScope,
+		directory.AdminDirectoryGroupReadonlyScope,
+		directory.AdminDirectoryGroupMemberReadonlyScope,
+		directory.AdminDirectoryOrgunitReadonlyScope,
+		iam.CloudPlatformScope,
+	}
+}
+
+// Assignee This is the user, group or service account a role is assigned to
+type Assignee struct {
+	Type      string
+	Id        string
+	Email     string
+	Suspended bool
+	// Members are the direct members of a group assignee, who inherit the role
+	Members []string
+}
+
+// Run lists the roles and their assignments and resolves the assignees
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+
+	log.Println("Getting the admin roles, privileges and assignments...")
+	roles, err := directoryAPI.GetRoles()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetRoles", err)
+	}
+	privileges, err := directoryAPI.GetPrivileges()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetPrivileges", err)
+	}
+	assignments, err := directoryAPI.GetRoleAssignments("")
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetRoleAssignments", err)
+	}
+	log.Printf("Total of %d roles and %d assignments found...", len(roles), len(assignments))
+
+	// Get the users and groups to resolve the assignees, resuming the listings of the previous run
+	users, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	groups, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "groups", func(pageToken string) ([]*directory.Group, string, error) {
+		return directoryAPI.QueryGroupsPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	assignees := receiver.resolve(ctx, clients, assignments, users, groups)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	orgUnits := receiver.orgUnits(clients, assignments)
+
+	rolesById := make(map[int64]*directory.Role)
+	assignmentCounts := make(map[int64]int)
+	for _, role := range roles {
+		rolesById[role.RoleId] = role
+	}
+	for _, assignment := range assignments {
+		assignmentCounts[assignment.RoleId]++
+	}
+
+	if err := writeRoles(sink, roles, privileges, assignmentCounts); err != nil {
+		return err
+	}
+	if err := writeAssignments(sink, assignments, rolesById, assignees, orgUnits); err != nil {
+		return err
+	}
+	return writeSummary(sink, roles, assignments, rolesById, assignees, users)
+}
+
+// resolve returns the assignee of every assignment by assigned id, the members of the groups are listed
+func (receiver *Auditor) resolve(ctx context.Context, clients *Audit.Clients, assignments []*directory.RoleAssignment, users []*directory.User, groups []*directory.Group) map[string]*Assignee {
+	usersById := make(map[string]*directory.User)
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+	groupsById := make(map[string]*directory.Group)
+	for _, group := range groups {
+		groupsById[group.Id] = group
+	}
+
+	assignees := make(map[string]*Assignee)
+	for _, assignment := range assignments {
+		if assignees[assignment.AssignedTo] != nil {
+			continue
+		}
+		assignee := &Assignee{Type: AssigneeUnknown, Id: assignment.AssignedTo}
+		if user, ok := usersById[assignment.AssignedTo]; ok {
+			assignee.Type, assignee.Email, assignee.Suspended = AssigneeUser, user.PrimaryEmail, user.Suspended
+		} else if group, ok := groupsById[assignment.AssignedTo]; ok || assignment.AssigneeType == AssigneeGroup {
+			assignee.Type = AssigneeGroup
+			if group != nil {
+				assignee.Email = group.Email
+				members, err := clients.Directory().GetGroupMembers(group, "")
+				if err != nil && ctx.Err() == nil {
+					clients.Errors.Add(receiver.Name(), "group", group.Email, Audit.Call("DirectoryAPI.GetGroupMembers", err))
+				}
+				for _, member := range members {
+					assignee.Members = append(assignee.Members, member.Email)
+				}
+			}
+		} else {
+			receiver.serviceAccount(ctx, clients, assignee)
+		}
+		assignees[assignment.AssignedTo] = assignee
+	}
+	return assignees
+}
+
+// serviceAccount resolves an assignee that is neither a user nor a group of the directory as the service account with its unique id.
+// The assignee stays unknown when IAM does not find the account.
+func (receiver *Auditor) serviceAccount(ctx context.Context, clients *Audit.Clients, assignee *Assignee) {
+	account, err := clients.Iam().GetServiceAccount(assignee.Id)
+	if err != nil {
+		if Audit.HTTPCode(err) != http.StatusNotFound && ctx.Err() == nil {
+			clients.Errors.Add(receiver.Name(), "assignee", assignee.Id, Audit.Call("IamAPI.GetServiceAccount", err))
+		}
+		return
+	}
+	assignee.Type, assignee.Email = AssigneeServiceAccount, account.Email
+}
+
+// orgUnits returns the tree of the organizational units the assignments are restricted to, nil when none is or the units cannot be listed
+func (receiver *Auditor) orgUnits(clients *Audit.Clients, assignments []*directory.RoleAssignment) *GoogleAPI.OrgUnitTree {
+	for _, assignment := range assignments {
+		if assignment.OrgUnitId == "" {
+			continue
+		}
+		orgUnits, err := clients.Directory().GetOrgUnits()
+		if err != nil {
+			clients.Errors.Add(receiver.Name(), "org_units", clients.Directory().Customer, Audit.Call("DirectoryAPI.GetOrgUnits", err))
+			return nil
+		}
+		return GoogleAPI.NewOrgUnitTree(orgUnits, nil)
+	}
+	return nil
+}
+
+// writeRoles writes every role with its privileges, flagging the custom roles granting high risk privileges
+func writeRoles(sink Report.Sink, roles []*directory.Role, privileges []*directory.Privilege, assignmentCounts map[int64]int) error {
+	services := privilegeServices(privileges)
+	var csvRows [][]any
+	for _, role := range roles {
+		var names, highRisk []string
+		for _, privilege := range role.RolePrivileges {
+			name := privilege.PrivilegeName
+			if service := services[privilege.ServiceId]; service != "" {
+				name = service + "/" + name
+			}
+			names = append(names, name)
+			if HighRiskPrivilege(privilege.PrivilegeName) {
+				highRisk = append(highRisk, name)
+			}
+		}
+		csvRows = append(csvRows, []any{
+			strconv.FormatInt(role.RoleId, 10),
+			role.RoleName,
+			role.RoleDescription,
+			role.IsSystemRole,
+			role.IsSuperAdminRole,
+			len(names),
+			names,
+			highRisk,
+			assignmentCounts[role.RoleId],
+			riskyCustomRole(role),
+		})
+	}
+	headers := []string{"ROLE_ID", "ROLE_NAME", "DESCRIPTION", "IS_SYSTEM_ROLE", "IS_SUPER_ADMIN_ROLE", "PRIVILEGE_COUNT", "PRIVILEGES",
+		"HIGH_RISK_PRIVILEGES", "ASSIGNMENT_COUNT", "RISKY_CUSTOM_ROLE"}
+	return Report.WriteAll(sink, "adminRoles", headers, csvRows)
+}
+
+// writeAssignments writes every role assignment with its resolved assignee and the path of the org unit it is restricted to
+func writeAssignments(sink Report.Sink, assignments []*directory.RoleAssignment, rolesById map[int64]*directory.Role, assignees map[string]*Assignee, orgUnits *GoogleAPI.OrgUnitTree) error {
+	var csvRows [][]any
+	for _, assignment := range assignments {
+		role := rolesById[assignment.RoleId]
+		if role == nil {
+			role = &directory.Role{RoleId: assignment.RoleId}
+		}
+		assignee := assignees[assignment.AssignedTo]
+		orgUnitPath := ""
+		if orgUnits != nil && assignment.OrgUnitId != "" {
+			if orgUnit := orgUnits.FindById(assignment.OrgUnitId); orgUnit != nil {
+				orgUnitPath = orgUnit.Path
+			}
+		}
+		csvRows = append(csvRows, []any{
+			strconv.FormatInt(assignment.RoleAssignmentId, 10),
+			strconv.FormatInt(assignment.RoleId, 10),
+			role.RoleName,
+			role.IsSuperAdminRole,
+			assignee.Type,
+			assignee.Id,
+			assignee.Email,
+			assignee.Suspended,
+			assignee.Members,
+			assignment.ScopeType,
+			assignment.OrgUnitId,
+			orgUnitPath,
+		})
+	}
+	headers := []string{"ASSIGNMENT_ID", "ROLE_ID", "ROLE_NAME", "IS_SUPER_ADMIN_ROLE", "ASSIGNEE_TYPE", "ASSIGNEE_ID", "ASSIGNEE_EMAIL",
+		"ASSIGNEE_SUSPENDED", "GROUP_MEMBERS", "SCOPE_TYPE", "ORG_UNIT_ID", "ORG_UNIT_PATH"}
+	return Report.WriteAll(sink, "adminRoleAssignments", headers, csvRows)
+}
+
+// writeSummary writes the super admin counts and the risky custom roles with their findings
+func writeSummary(sink Report.Sink, roles []*directory.Role, assignments []*directory.RoleAssignment, rolesById map[int64]*directory.Role, assignees map[string]*Assignee, users []*directory.User) error {
+	var superAdmins, suspendedSuperAdmins, delegatedAdmins int
+	for _, user := range users {
+		switch {
+		case user.IsAdmin && user.Suspended:
+			suspendedSuperAdmins++
+		case user.IsAdmin:
+			superAdmins++
+		case user.IsDelegatedAdmin:
+			delegatedAdmins++
+		}
+	}
+	superAdminAssignees := make(map[string]map[string]bool)
+	for _, assignment := range assignments {
+		if role := rolesById[assignment.RoleId]; role != nil && role.IsSuperAdminRole {
+			assignee := assignees[assignment.AssignedTo]
+			if superAdminAssignees[assignee.Type] == nil {
+				superAdminAssignees[assignee.Type] = make(map[string]bool)
+			}
+			superAdminAssignees[assignee.Type][assignee.Id] = true
+		}
+	}
+	var customRoles, riskyCustomRoles int
+	for _, role := range roles {
+		if !role.IsSystemRole {
+			customRoles++
+		}
+		if riskyCustomRole(role) {
+			riskyCustomRoles++
+		}
+	}
+
+	superAdminFinding := ""
+	if superAdmins > MaxSuperAdmins {
+		superAdminFinding = fmt.Sprintf("more than %d super admins", MaxSuperAdmins)
+	} else if superAdmins < 2 {
+		superAdminFinding = "fewer than 2 super admins, there is no backup super admin"
+	}
+	csvRows := [][]any{
+		{"super_admin_users", superAdmins, superAdminFinding},
+		{"suspended_super_admin_users", suspendedSuperAdmins, ""},
+		{"super_admin_groups", len(superAdminAssignees[AssigneeGroup]), finding(len(superAdminAssignees[AssigneeGroup]) > 0, "the members of these groups are super admins")},
+		{"super_admin_service_accounts", len(superAdminAssignees[AssigneeServiceAccount]), finding(len(superAdminAssignees[AssigneeServiceAccount]) > 0, "service accounts hold the super admin role")},
+		{"super_admin_unknown_assignees", len(superAdminAssignees[AssigneeUnknown]), finding(len(superAdminAssignees[AssigneeUnknown]) > 0, "the super admin role is assigned to unknown ids, see adminRoleAssignments")},
+		{"delegated_admin_users", delegatedAdmins, ""},
+		{"custom_roles", customRoles, ""},
+		{"risky_custom_roles", riskyCustomRoles, finding(riskyCustomRoles > 0, "custom roles grant high risk privileges, see adminRoles")},
+	}
+	log.Printf("%d super admins, %d risky custom roles", superAdmins, riskyCustomRoles)
+	headers := []string{"METRIC", "VALUE", "FINDING"}
+	return Report.WriteAll(sink, "adminSummary", headers, csvRows)
+}
+
+// riskyCustomRole returns true when a custom role grants a high risk privilege
+func riskyCustomRole(role *directory.Role) bool {
+	if role.IsSystemRole {
+		return false
+	}
+	for _, privilege := range role.RolePrivileges {
+		if HighRiskPrivilege(privilege.PrivilegeName) {
+			return true
+		}
+	}
+	return false
+}
+
+// finding returns the message when the condition is true
+func finding(condition bool, message string) string {
+	if condition {
+		return message
+	}
+	return ""
+}
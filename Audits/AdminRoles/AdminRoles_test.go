@@ -0,0 +1,51 @@
+package AdminRoles
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/FakeGoogleAPI"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/iam/v1"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	users := []*directory.User{{Id: "1", PrimaryEmail: "admin@example.com"}}
+	groups := []*directory.Group{{Id: "g1", Email: "admins@example.com"}}
+	server := FakeGoogleAPI.NewServer(&FakeGoogleAPI.Tenant{
+		Users:  users,
+		Groups: groups,
+		ServiceAccounts: map[string][]*iam.ServiceAccount{
+			"project-a": {{Email: "sa@project-a.iam.gserviceaccount.com", ProjectId: "project-a", UniqueId: "123"}},
+		},
+	})
+	defer server.Close()
+	clients := Audit.NewClients(server.Client(), nil, context.Background())
+
+	assignments := []*directory.RoleAssignment{
+		{AssignedTo: "1", AssigneeType: "user"},
+		{AssignedTo: "g1", AssigneeType: "group"},
+		{AssignedTo: "123", AssigneeType: "user"},
+		// A deleted user is not a service account
+		{AssignedTo: "999", AssigneeType: "user"},
+	}
+	assignees := (&Auditor{}).resolve(context.Background(), clients, assignments, users, groups)
+
+	tests := []struct {
+		id, wantType, wantEmail string
+	}{
+		{"1", AssigneeUser, "admin@example.com"},
+		{"g1", AssigneeGroup, "admins@example.com"},
+		{"123", AssigneeServiceAccount, "sa@project-a.iam.gserviceaccount.com"},
+		{"999", AssigneeUnknown, ""},
+	}
+	for _, test := range tests {
+		assignee := assignees[test.id]
+		if assignee == nil || assignee.Type != test.wantType || assignee.Email != test.wantEmail {
+			t.Errorf("assignee %s = %+v, want %s %q", test.id, assignee, test.wantType, test.wantEmail)
+		}
+	}
+	if entityErrors := clients.Errors.Errors(); len(entityErrors) != 0 {
+		t.Errorf("resolve recorded %d errors, want none for the unknown id", len(entityErrors))
+	}
+}
@@ -0,0 +1,56 @@
+package AdminRoles
+
+import (
+	directory "google.golang.org/api/admin/directory/v1"
+	"strings"
+)
+
+// MaxSuperAdmins This is the number of super admins above which the audit reports a finding, fewer than 2 leaves no backup
+const MaxSuperAdmins = 4
+
+// highRiskPrivileges are the privileges that let an admin take over accounts, change the security settings or grant roles.
+// They are matched by their exact name, so the read-only privileges like USERS_RETRIEVE or SECURITY_DASHBOARD_VIEW are never flagged.
+var highRiskPrivileges = map[string]bool{
+	"SUPER_ADMIN":                     true,
+	"USERS_ALL":                       true,
+	"USERS_CREATE":                    true,
+	"USERS_UPDATE":                    true,
+	"USERS_DELETE":                    true,
+	"USERS_ALIAS":                     true,
+	"USERS_RESET_PASSWORD":            true,
+	"USERS_FORCE_PASSWORD_CHANGE":     true,
+	"USER_SECURITY_ALL":               true,
+	"GROUPS_ALL":                      true,
+	"GROUPS_UPDATE":                   true,
+	"ORGANIZATION_UNITS_ALL":          true,
+	"ROLE_MANAGEMENT":                 true,
+	"SECURITY_SETTINGS":               true,
+	"MANAGE_SECURITY":                 true,
+	"DOMAIN_MANAGEMENT":               true,
+	"DATA_TRANSFER":                   true,
+	"MANAGE_SERVICE_ACCOUNTS":         true,
+	"API_CLIENT_ACCESS":               true,
+	"MANAGE_API_ACCESS":               true,
+	"MANAGE_THIRD_PARTY_APP_SECURITY": true,
+}
+
+// HighRiskPrivilege returns true when the privilege allows taking over accounts, changing the security settings or granting roles
+func HighRiskPrivilege(privilegeName string) bool {
+	return highRiskPrivileges[strings.ToUpper(privilegeName)]
+}
+
+// privilegeServices returns the service names of the privileges and of their children by service id
+func privilegeServices(privileges []*directory.Privilege) map[string]string {
+	services := make(map[string]string)
+	var add func(privileges []*directory.Privilege)
+	add = func(privileges []*directory.Privilege) {
+		for _, privilege := range privileges {
+			if privilege.ServiceName != "" {
+				services[privilege.ServiceId] = privilege.ServiceName
+			}
+			add(privilege.ChildPrivileges)
+		}
+	}
+	add(privileges)
+	return services
+}
@@ -0,0 +1,39 @@
+package AdminRoles
+
+import "testing"
+
+func TestHighRiskPrivilege(t *testing.T) {
+	tests := []struct {
+		privilegeName string
+		want          bool
+	}{
+		// Write privileges
+		{"USERS_CREATE", true},
+		{"USERS_UPDATE", true},
+		{"USERS_DELETE", true},
+		{"USERS_ALL", true},
+		{"users_reset_password", true},
+		{"GROUPS_ALL", true},
+		{"ROLE_MANAGEMENT", true},
+		{"SECURITY_SETTINGS", true},
+		{"DOMAIN_MANAGEMENT", true},
+		{"DATA_TRANSFER", true},
+		{"MANAGE_SERVICE_ACCOUNTS", true},
+		{"API_CLIENT_ACCESS", true},
+		// Read-only privileges, even when their name contains the name of a high risk privilege
+		{"USERS_RETRIEVE", false},
+		{"GROUPS_RETRIEVE", false},
+		{"ORGANIZATION_UNITS_RETRIEVE", false},
+		{"ROLE_MANAGEMENT_VIEW", false},
+		{"SECURITY_DASHBOARD_VIEW", false},
+		{"DOMAIN_MANAGEMENT_READ", false},
+		{"REPORTS_ACCESS_ALL", false},
+		{"API_CLIENT_ACCESS_VIEW", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := HighRiskPrivilege(test.privilegeName); got != test.want {
+			t.Errorf("HighRiskPrivilege(%q) = %v, want %v", test.privilegeName, got, test.want)
+		}
+	}
+}
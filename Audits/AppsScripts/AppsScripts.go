@@ -0,0 +1,131 @@
+package AppsScripts
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/drive/v3"
+	"log"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Auditor This is the audit that lists the Google Apps Scripts owned by every user
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "apps-scripts"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Google Apps Scripts owned by every user (requires -key_path)"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{directory.AdminDirectoryUserReadonlyScope}
+}
+
+// DelegatedScopes returns the scopes requested when impersonating the users
+func (receiver *Auditor) DelegatedScopes() []string {
+	return []string{drive.DriveReadonlyScope}
+}
+
+// Script This is a Google Apps Script owned by a user
+type Script struct {
+	Owner            string `json:"owner"`
+	OwnerOrgUnitPath string `json:"owner_org_unit_path"`
+	Id               string `json:"id"`
+	Name             string `json:"name"`
+	Created          string `json:"created"`
+	LastViewed       string `json:"last_viewed"`
+	Shared           bool   `json:"shared"`
+	TeamDriveId      string `json:"team_drive_id"`
+}
+
+// Row returns the row of the script in the userOwnedGoogleAppsScripts report
+func (receiver *Script) Row() []any {
+	return []any{receiver.Owner, receiver.OwnerOrgUnitPath, receiver.Id, receiver.Name, receiver.Created, receiver.LastViewed, receiver.Shared, receiver.TeamDriveId}
+}
+
+// Run audits all the Google Apps Scripts in the domain
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	// Initialize the Google Directory API
+	directoryAPI := clients.Directory()
+
+	// Pull all the users from the domain, resuming the listing of the previous run
+	log.Printf("Pulling all users from the domain...")
+	allUsers, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	allUsers = clients.FilterUsers(allUsers)
+
+	// Loop through all the users with the worker pool, skipping the users scanned by the previous run
+	log.Printf("Looping through %d users...", len(allUsers))
+	results := Checkpoint.Run(ctx, clients.Checkpoint, receiver.Name(), "users", allUsers, func(user *directory.User) string {
+		return user.PrimaryEmail
+	}, WorkerPool.Options{
+		Concurrency: clients.Concurrency,
+		Progress:    WorkerPool.LogProgress("Scanned users", 10),
+	}, func(ctx context.Context, user *directory.User) ([]*Script, error) {
+		// Set the user's primary email as the subject for the JWT
+		log.Printf("Scanning user: %s", user.PrimaryEmail)
+		driveAPI := clients.DelegatedDrive(user.PrimaryEmail, receiver.DelegatedScopes())
+		// Stream the Google Apps Scripts owned by the user page by page, only their rows are kept
+		var scripts []*Script
+		for item := range driveAPI.StreamFiles("mimeType='application/vnd.google-apps.script' AND 'me' in owners") {
+			if item.Err != nil {
+				return nil, Audit.Call("DriveAPI.StreamFiles", item.Err)
+			}
+			file := item.Value
+			log.Println(file.Name)
+			scripts = append(scripts, &Script{
+				Owner:            user.PrimaryEmail,
+				OwnerOrgUnitPath: user.OrgUnitPath,
+				Id:               file.Id,
+				Name:             file.Name,
+				Created:          file.CreatedTime,
+				LastViewed:       file.ViewedByMeTime,
+				Shared:           file.Shared,
+				TeamDriveId:      file.TeamDriveId})
+		}
+		// The stream is closed without an error once the context is done, the user is scanned again by the next run
+		if err := driveAPI.Ctx.Err(); err != nil {
+			return nil, err
+		}
+		return scripts, nil
+	})
+
+	// Record the users whose Drive could not be scanned, for example the suspended users
+	Audit.RecordFailures(ctx, clients.Errors, receiver.Name(), "user", allUsers, func(user *directory.User) string {
+		return user.PrimaryEmail
+	}, results)
+
+	// Create rows for the csv
+	var csvRows [][]any
+	for _, scripts := range WorkerPool.Values(results) {
+		for _, script := range scripts {
+			csvRows = append(csvRows, script.Row())
+		}
+	}
+	headers := []string{"OWNER", "OWNER_ORG_UNIT_PATH", "FILE_ID", "FILE_NAME", "CREATED", "LAST_VIEWED", "SHARED", "TEAM_DRIVE_ID"}
+
+	// Write the scripts of the users scanned so far when the context is done
+	if incomplete := WorkerPool.Incomplete(ctx, "users", results); incomplete != nil {
+		if err := Report.WriteAll(sink, "userOwnedGoogleAppsScripts", headers, csvRows); err != nil {
+			return err
+		}
+		return incomplete
+	}
+	return Report.WriteAll(sink, "userOwnedGoogleAppsScripts", headers, csvRows)
+}
@@ -0,0 +1,179 @@
+package Devices
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	directory "google.golang.org/api/admin/directory/v1"
+	"log"
+	"strings"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Types of the devices
+const (
+	TypeMobile   = "mobile"
+	TypeChromeOS = "chromeos"
+)
+
+// Auditor This is the inventory of the mobile and Chrome OS devices and of their posture
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "devices"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Mobile and Chrome OS devices with their owner, OS version, last sync and security posture"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryDeviceMobileReadonlyScope,
+		directory.AdminDirectoryDeviceChromeosReadonlyScope,
+		directory.AdminDirectoryUserReadonlyScope,
+	}
+}
+
+// Device This is a mobile or Chrome OS device with the fields of both kinds that describe its posture
+type Device struct {
+	Type         string
+	Id           string
+	SerialNumber string
+	Owners       []string
+	// OrgUnitPath is the org unit of a Chrome OS device, or of the first owner of a mobile device
+	OrgUnitPath string
+	// ManagementType is the management of a mobile device, like ANDROID, IOS_SYNC or GOOGLE_SYNC, and CHROME_OS for the enrolled Chrome OS devices
+	ManagementType string
+	Status         string
+	Model          string
+	OsVersion      string
+	FirstSync      string
+	LastSync       string
+	// CompromisedStatus, EncryptionStatus and PasswordStatus are only reported by the mobile devices
+	CompromisedStatus string
+	EncryptionStatus  string
+	PasswordStatus    string
+}
+
+// NewMobileDevice returns the Device of a mobile device
+func NewMobileDevice(device *directory.MobileDevice) *Device {
+	return &Device{
+		Type:              TypeMobile,
+		Id:                device.ResourceId,
+		SerialNumber:      device.SerialNumber,
+		Owners:            GoogleAPI.MobileDeviceOwners(device),
+		ManagementType:    device.Type,
+		Status:            device.Status,
+		Model:             strings.TrimSpace(device.Manufacturer + " " + device.Model),
+		OsVersion:         device.Os,
+		FirstSync:         device.FirstSync,
+		LastSync:          device.LastSync,
+		CompromisedStatus: device.DeviceCompromisedStatus,
+		EncryptionStatus:  device.EncryptionStatus,
+		PasswordStatus:    device.DevicePasswordStatus,
+	}
+}
+
+// NewChromeOSDevice returns the Device of a Chrome OS device
+func NewChromeOSDevice(device *directory.ChromeOsDevice) *Device {
+	return &Device{
+		Type:           TypeChromeOS,
+		Id:             device.DeviceId,
+		SerialNumber:   device.SerialNumber,
+		Owners:         GoogleAPI.ChromeOSDeviceOwners(device),
+		OrgUnitPath:    device.OrgUnitPath,
+		ManagementType: "CHROME_OS",
+		Status:         device.Status,
+		Model:          device.Model,
+		OsVersion:      device.OsVersion,
+		FirstSync:      device.FirstEnrollmentTime,
+		LastSync:       device.LastSync,
+	}
+}
+
+// Run lists the devices and links them to the users of the directory
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+
+	log.Println("Getting the mobile and Chrome OS devices...")
+	mobileDevices, err := directoryAPI.GetMobileDevices()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetMobileDevices", err)
+	}
+	chromeOSDevices, err := directoryAPI.GetChromeOSDevices()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetChromeOSDevices", err)
+	}
+	log.Printf("Total of %d mobile and %d Chrome OS devices found...", len(mobileDevices), len(chromeOSDevices))
+
+	// Get all the users of the domain to link the devices to their owners, resuming the listing of the previous run
+	allUsers, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	users := make(map[string]*directory.User)
+	for _, user := range allUsers {
+		users[strings.ToLower(user.PrimaryEmail)] = user
+	}
+
+	var devices []*Device
+	for _, device := range mobileDevices {
+		mobile := NewMobileDevice(device)
+		if len(mobile.Owners) > 0 && users[mobile.Owners[0]] != nil {
+			mobile.OrgUnitPath = users[mobile.Owners[0]].OrgUnitPath
+		}
+		devices = append(devices, mobile)
+	}
+	for _, device := range chromeOSDevices {
+		devices = append(devices, NewChromeOSDevice(device))
+	}
+
+	var csvRows [][]any
+	for _, device := range devices {
+		// Keep the devices of the org unit subtree of the run, the mobile devices of unknown owners only when every user is audited
+		if clients.OrgUnit != "" && (device.OrgUnitPath == "" || !clients.InOrgUnit(device.OrgUnitPath)) {
+			continue
+		}
+		var ownerSuspended bool
+		var unknownOwners []string
+		for _, owner := range device.Owners {
+			if user := users[owner]; user == nil {
+				unknownOwners = append(unknownOwners, owner)
+			} else if user.Suspended || user.Archived {
+				ownerSuspended = true
+			}
+		}
+		csvRows = append(csvRows, []any{
+			device.Type,
+			device.Id,
+			device.SerialNumber,
+			device.Owners,
+			device.OrgUnitPath,
+			ownerSuspended,
+			unknownOwners,
+			device.ManagementType,
+			device.Status,
+			device.Model,
+			device.OsVersion,
+			device.FirstSync,
+			device.LastSync,
+			device.CompromisedStatus,
+			device.EncryptionStatus,
+			device.PasswordStatus,
+		})
+	}
+	headers := []string{"TYPE", "DEVICE_ID", "SERIAL_NUMBER", "OWNERS", "ORG_UNIT_PATH", "OWNER_SUSPENDED", "UNKNOWN_OWNERS", "MANAGEMENT_TYPE",
+		"STATUS", "MODEL", "OS_VERSION", "FIRST_SYNC", "LAST_SYNC", "COMPROMISED_STATUS", "ENCRYPTION_STATUS", "PASSWORD_STATUS"}
+	return Report.WriteAll(sink, "devices", headers, csvRows)
+}
@@ -0,0 +1,290 @@
+package Groups
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	directory "google.golang.org/api/admin/directory/v1"
+	"log"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Auditor This is the audit that maps the owners, managers, parent groups and effective nested members of every group,
+// and finds the groups left without an active owner
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "groups"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Owners, managers, parent groups and effective nested members of every group, and groups without an active owner"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryGroupReadonlyScope,
+		directory.AdminDirectoryGroupMemberReadonlyScope,
+		directory.AdminDirectoryDomainReadonlyScope,
+		directory.AdminDirectoryUserReadonlyScope,
+	}
+}
+
+// GroupMap This is the owners, managers, parent groups and effective members of a group
+type GroupMap struct {
+	Email         string   `json:"email"`
+	MembersCount  int64    `json:"members_count"`
+	Owners        []string `json:"owners"`
+	Managers      []string `json:"managers"`
+	Subscriptions []string `json:"subscriptions"`
+	// EffectiveMembersCount is the number of direct and inherited members
+	EffectiveMembersCount int `json:"effective_members_count"`
+	// ExternalUsersCount, ExternalGroupsCount and CustomerCount are the numbers of direct members of every classification
+	ExternalUsersCount  int `json:"external_users_count"`
+	ExternalGroupsCount int `json:"external_groups_count"`
+	CustomerCount       int `json:"customer_count"`
+}
+
+// Row returns the row of the group in the groupsMap report
+func (receiver *GroupMap) Row() []any {
+	return []any{receiver.Email, // Group email
+		receiver.MembersCount,          // Members count
+		len(receiver.Owners),           // Owners count
+		receiver.Owners,                // Owners
+		len(receiver.Managers),         // Managers count
+		receiver.Managers,              // Managers
+		len(receiver.Subscriptions),    // Subscriptions count
+		receiver.Subscriptions,         // Subscriptions
+		receiver.EffectiveMembersCount, // Effective members count
+		receiver.ExternalUsersCount,    // External users count
+		receiver.ExternalGroupsCount,   // External groups count
+		receiver.CustomerCount,         // Customer members count
+	}
+}
+
+// Run will get all the groups and their members
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+
+	//Get all the groups of the domain, resuming the listing of the previous run
+	log.Println("Getting all groups...")
+	allGroups, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "groups", func(pageToken string) ([]*directory.Group, string, error) {
+		return directoryAPI.QueryGroupsPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Total of %d groups found...", len(allGroups))
+
+	// Sort the groups by member count
+	log.Println("Sorting groups by member count...")
+	sort.SliceStable(allGroups, func(i, j int) bool {
+		return allGroups[i].DirectMembersCount > allGroups[j].DirectMembersCount
+	})
+	log.Println("Sorted all groups")
+
+	// Get the direct members of every group with the worker pool, skipping the groups of the previous run
+	results := Checkpoint.Run(ctx, clients.Checkpoint, receiver.Name(), "members", allGroups, func(group *directory.Group) string {
+		return group.Email
+	}, WorkerPool.Options{
+		Concurrency: clients.Concurrency,
+		Progress:    WorkerPool.LogProgress("Groups", 100),
+	}, func(ctx context.Context, group *directory.Group) ([]*directory.Member, error) {
+		members, err := directoryAPI.GetGroupMembers(group, "")
+		if err != nil {
+			return nil, Audit.Call("DirectoryAPI.GetGroupMembers", err)
+		}
+		return members, nil
+	})
+
+	// Record the groups that failed, they are left out of the reports
+	Audit.RecordFailures(ctx, clients.Errors, receiver.Name(), "group", allGroups, func(group *directory.Group) string {
+		return group.Email
+	}, results)
+
+	// Expand the groups nested in other groups
+	members := make(map[string][]*directory.Member)
+	var groups []*directory.Group
+	for _, result := range results {
+		if result.Err == nil {
+			members[allGroups[result.Index].Email] = result.Value
+			groups = append(groups, allGroups[result.Index])
+		}
+	}
+	graph := GoogleAPI.NewMembershipGraph(members)
+	for _, cycle := range graph.Cycles {
+		log.Printf("Groups nested in a cycle: %s", strings.Join(cycle, " > "))
+	}
+
+	// Classify the members against the domains of the organization, or the domains of the groups when they are unknown
+	domains := clients.OrganizationDomains()
+	if len(domains) == 0 {
+		domains = Audit.Domains{}
+		for _, group := range allGroups {
+			domains[Audit.Domain(group.Email)] = true
+		}
+		log.Printf("No domains known, the members of domains other than %s are external", strings.Join(domains.List(), ", "))
+	}
+
+	// Join the owners with every user of the customer, whatever the org unit, to find the suspended, archived and deleted owners
+	// A partial listing would report the users not listed yet as deleted, the users are only joined once all are listed
+	var users map[string]*directory.User
+	log.Println("Getting all users...")
+	allUsers, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			clients.Errors.Add(receiver.Name(), "users", directoryAPI.Customer, Audit.Call("DirectoryAPI.QueryUsers", err))
+		}
+		log.Printf("Unable to get the users, the owners are only checked against the status of their membership: %s", err.Error())
+	} else {
+		users = make(map[string]*directory.User, len(allUsers))
+		for _, user := range allUsers {
+			users[strings.ToLower(user.PrimaryEmail)] = user
+		}
+	}
+
+	// Write the groups collected so far when the context is done
+	incomplete := WorkerPool.Incomplete(ctx, "groups", results)
+	if err := writeGroupsMap(sink, graph, groups, domains); err != nil {
+		return err
+	}
+	if err := writeExternalMembers(sink, graph, groups, domains); err != nil {
+		return err
+	}
+	if err := writeOrphanedGroups(sink, graph, groups, users, domains); err != nil {
+		return err
+	}
+	if err := writeEffectiveMembers(sink, graph); err != nil {
+		return err
+	}
+	if err := writeCycles(sink, graph); err != nil {
+		return err
+	}
+	return incomplete
+}
+
+// writeGroupsMap writes the owners, managers and parent groups of every group to the groupsMap report
+func writeGroupsMap(sink Report.Sink, graph *GoogleAPI.MembershipGraph, groups []*directory.Group, domains Audit.Domains) error {
+	var csvRows [][]any
+	for _, group := range groups {
+		groupMap := &GroupMap{
+			Email:                 group.Email,
+			MembersCount:          group.DirectMembersCount,
+			Subscriptions:         graph.Parents(group.Email),
+			EffectiveMembersCount: len(graph.Effective(group.Email)),
+		}
+		for _, member := range graph.Members[strings.ToLower(group.Email)] {
+			switch member.Role {
+			case "OWNER":
+				groupMap.Owners = append(groupMap.Owners, member.Email)
+			case "MANAGER":
+				groupMap.Managers = append(groupMap.Managers, member.Email)
+			}
+			switch domains.Classify(member) {
+			case Audit.MemberExternalUser:
+				groupMap.ExternalUsersCount++
+			case Audit.MemberExternalGroup:
+				groupMap.ExternalGroupsCount++
+			case Audit.MemberCustomer:
+				groupMap.CustomerCount++
+			}
+		}
+		csvRows = append(csvRows, groupMap.Row())
+	}
+	headers := []string{"GROUP_EMAIL", "MEMBERS_COUNT", "OWNER_COUNT", "OWNERS", "MANAGER_COUNT", "MANAGERS", "SUB_COUNT", "SUBSCRIPTIONS", "EFFECTIVE_MEMBERS_COUNT", "EXTERNAL_USER_COUNT", "EXTERNAL_GROUP_COUNT", "CUSTOMER_COUNT"}
+	return Report.WriteAll(sink, "groupsMap", headers, csvRows)
+}
+
+// writeExternalMembers writes the direct members of every group that are not in the domains of the organization
+func writeExternalMembers(sink Report.Sink, graph *GoogleAPI.MembershipGraph, groups []*directory.Group, domains Audit.Domains) error {
+	var csvRows [][]any
+	for _, group := range groups {
+		for _, member := range graph.Members[strings.ToLower(group.Email)] {
+			classification := domains.Classify(member)
+			if classification != Audit.MemberExternalUser && classification != Audit.MemberExternalGroup {
+				continue
+			}
+			csvRows = append(csvRows, []any{
+				group.Email,
+				member.Email,
+				Audit.Domain(member.Email),
+				classification,
+				member.Type,
+				member.Role,
+				member.Status,
+			})
+		}
+	}
+	headers := []string{"GROUP_EMAIL", "MEMBER_EMAIL", "MEMBER_DOMAIN", "CLASSIFICATION", "MEMBER_TYPE", "ROLE", "STATUS"}
+	return Report.WriteAll(sink, "external_members", headers, csvRows)
+}
+
+// writeEffectiveMembers writes the direct and inherited members of every group, and the groups of every user
+func writeEffectiveMembers(sink Report.Sink, graph *GoogleAPI.MembershipGraph) error {
+	var csvRows [][]any
+	for _, group := range graph.Groups() {
+		for _, membership := range graph.Effective(group) {
+			csvRows = append(csvRows, []any{
+				membership.Group,
+				membership.Member,
+				membership.Type,
+				membership.Role,
+				membership.Status,
+				membership.Depth,
+				membership.Inherited(),
+				membership.Path,
+			})
+		}
+	}
+	headers := []string{"GROUP_EMAIL", "MEMBER", "MEMBER_TYPE", "ROLE", "STATUS", "DEPTH", "INHERITED", "PATH"}
+	if err := Report.WriteAll(sink, "groupsEffectiveMembers", headers, csvRows); err != nil {
+		return err
+	}
+
+	// Group the memberships of the users, sorted by email
+	byMember := graph.ByMember()
+	var users []string
+	for member, memberships := range byMember {
+		if memberships[0].Type == "USER" {
+			users = append(users, member)
+		}
+	}
+	sort.Strings(users)
+	csvRows = nil
+	for _, user := range users {
+		var direct, inherited []string
+		for _, membership := range byMember[user] {
+			if membership.Inherited() {
+				inherited = append(inherited, membership.Group)
+			} else {
+				direct = append(direct, membership.Group)
+			}
+		}
+		csvRows = append(csvRows, []any{user, len(direct), direct, len(inherited), inherited})
+	}
+	headers = []string{"USER_EMAIL", "DIRECT_GROUP_COUNT", "DIRECT_GROUPS", "INHERITED_GROUP_COUNT", "INHERITED_GROUPS"}
+	return Report.WriteAll(sink, "usersEffectiveGroups", headers, csvRows)
+}
+
+// writeCycles writes the groups nested in themselves
+func writeCycles(sink Report.Sink, graph *GoogleAPI.MembershipGraph) error {
+	var csvRows [][]any
+	for _, cycle := range graph.Cycles {
+		csvRows = append(csvRows, []any{cycle[0], len(cycle) - 1, cycle})
+	}
+	headers := []string{"GROUP_EMAIL", "CYCLE_LENGTH", "CYCLE"}
+	return Report.WriteAll(sink, "groupsCycles", headers, csvRows)
+}
@@ -0,0 +1,120 @@
+package Groups
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	directory "google.golang.org/api/admin/directory/v1"
+	"strings"
+)
+
+// Statuses of the owners of a group
+const (
+	OwnerActive    = "active"    // An internal user that can manage the group, or an owner that is not a user
+	OwnerSuspended = "suspended" // A suspended user of the customer
+	OwnerArchived  = "archived"  // An archived user of the customer
+	OwnerDeleted   = "deleted"   // An internal user that is no longer a user of the customer
+	OwnerExternal  = "external"  // A user or group of another domain
+)
+
+// Findings of the orphanedGroups report, a group is orphaned when none of its owners is active
+const (
+	FindingNoOwners                 = "no_owners"                        // The group has no owner
+	FindingInactiveOwnersOnly       = "inactive_owners_only"             // Every owner is suspended, archived or deleted
+	FindingExternalOwnersOnly       = "external_owners_only"             // Every owner is an external account
+	FindingInactiveOrExternalOwners = "inactive_or_external_owners_only" // Every owner is inactive or external
+)
+
+// OrphanedGroup This is a group none of whose owners is an active user of the organization
+type OrphanedGroup struct {
+	Email        string `json:"email"`
+	Finding      string `json:"finding"`
+	MembersCount int64  `json:"members_count"`
+	// Owners are the owners of the group and OwnerStatuses their status, in the same order
+	Owners        []string `json:"owners"`
+	OwnerStatuses []string `json:"owner_statuses"`
+	// Managers can still manage the members of the group and be promoted to owner
+	Managers []string `json:"managers"`
+}
+
+// Row returns the row of the group in the orphanedGroups report
+func (receiver *OrphanedGroup) Row() []any {
+	return []any{receiver.Email, // Group email
+		receiver.Finding,       // Finding
+		receiver.MembersCount,  // Members count
+		len(receiver.Owners),   // Owners count
+		receiver.Owners,        // Owners
+		receiver.OwnerStatuses, // Owner statuses
+		len(receiver.Managers), // Managers count
+		receiver.Managers,      // Managers
+	}
+}
+
+// ownerStatus returns the status of an owner of a group, joined with the users of the customer by primary email.
+// The internal users are only known to be deleted when the users are, nil users leaves them active unless their membership is suspended.
+func ownerStatus(owner *directory.Member, users map[string]*directory.User, domains Audit.Domains) string {
+	switch domains.Classify(owner) {
+	case Audit.MemberExternalUser, Audit.MemberExternalGroup:
+		return OwnerExternal
+	}
+	if owner.Type != "USER" {
+		return OwnerActive
+	}
+	user, found := users[strings.ToLower(owner.Email)]
+	switch {
+	case users != nil && !found:
+		return OwnerDeleted
+	case owner.Status == "SUSPENDED" || (found && user.Suspended):
+		return OwnerSuspended
+	case found && user.Archived:
+		return OwnerArchived
+	}
+	return OwnerActive
+}
+
+// orphanedGroup returns the group when none of its owners is active, nil otherwise
+func orphanedGroup(group *directory.Group, members []*directory.Member, users map[string]*directory.User, domains Audit.Domains) *OrphanedGroup {
+	orphaned := &OrphanedGroup{Email: group.Email, MembersCount: group.DirectMembersCount}
+	inactive, external := 0, 0
+	for _, member := range members {
+		switch member.Role {
+		case "OWNER":
+			status := ownerStatus(member, users, domains)
+			switch status {
+			case OwnerActive:
+				return nil
+			case OwnerExternal:
+				external++
+			default:
+				inactive++
+			}
+			orphaned.Owners = append(orphaned.Owners, member.Email)
+			orphaned.OwnerStatuses = append(orphaned.OwnerStatuses, status)
+		case "MANAGER":
+			orphaned.Managers = append(orphaned.Managers, member.Email)
+		}
+	}
+	switch {
+	case len(orphaned.Owners) == 0:
+		orphaned.Finding = FindingNoOwners
+	case external == 0:
+		orphaned.Finding = FindingInactiveOwnersOnly
+	case inactive == 0:
+		orphaned.Finding = FindingExternalOwnersOnly
+	default:
+		orphaned.Finding = FindingInactiveOrExternalOwners
+	}
+	return orphaned
+}
+
+// writeOrphanedGroups writes the groups without owners, or whose owners are all suspended, archived, deleted or external
+func writeOrphanedGroups(sink Report.Sink, graph *GoogleAPI.MembershipGraph, groups []*directory.Group, users map[string]*directory.User, domains Audit.Domains) error {
+	var csvRows [][]any
+	for _, group := range groups {
+		if orphaned := orphanedGroup(group, graph.Members[strings.ToLower(group.Email)], users, domains); orphaned != nil {
+			csvRows = append(csvRows, orphaned.Row())
+		}
+	}
+	headers := []string{"GROUP_EMAIL", "FINDING", "MEMBERS_COUNT", "OWNER_COUNT", "OWNERS", "OWNER_STATUSES", "MANAGER_COUNT", "MANAGERS"}
+	return Report.WriteAll(sink, "orphanedGroups", headers, csvRows)
+}
@@ -0,0 +1,69 @@
+package Groups
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	directory "google.golang.org/api/admin/directory/v1"
+	"reflect"
+	"testing"
+)
+
+func TestOrphanedGroup(t *testing.T) {
+	users := map[string]*directory.User{
+		"alice@example.com": {PrimaryEmail: "alice@example.com"},
+		"bob@example.com":   {PrimaryEmail: "bob@example.com", Suspended: true},
+		"dave@example.com":  {PrimaryEmail: "dave@example.com", Archived: true},
+	}
+	domains := Audit.NewDomains("example.com")
+	owner := func(email string) *directory.Member {
+		return &directory.Member{Email: email, Role: "OWNER", Type: "USER"}
+	}
+	manager := &directory.Member{Email: "carol@example.com", Role: "MANAGER", Type: "USER"}
+	for _, test := range []struct {
+		name     string
+		members  []*directory.Member
+		users    map[string]*directory.User
+		finding  string
+		statuses []string
+	}{
+		{"no owners", []*directory.Member{manager}, users, FindingNoOwners, nil},
+		{"no members", nil, users, FindingNoOwners, nil},
+		{"suspended owner", []*directory.Member{owner("bob@example.com")}, users, FindingInactiveOwnersOnly, []string{OwnerSuspended}},
+		{"archived owner", []*directory.Member{owner("dave@example.com")}, users, FindingInactiveOwnersOnly, []string{OwnerArchived}},
+		{"deleted owner", []*directory.Member{owner("gone@example.com")}, users, FindingInactiveOwnersOnly, []string{OwnerDeleted}},
+		{"suspended membership", []*directory.Member{{Email: "alice@example.com", Role: "OWNER", Type: "USER", Status: "SUSPENDED"}}, users, FindingInactiveOwnersOnly, []string{OwnerSuspended}},
+		{"inactive owners", []*directory.Member{owner("bob@example.com"), owner("dave@example.com"), owner("gone@example.com")}, users, FindingInactiveOwnersOnly, []string{OwnerSuspended, OwnerArchived, OwnerDeleted}},
+		{"external owners", []*directory.Member{owner("partner@other.org"), {Email: "team@other.org", Role: "OWNER", Type: "GROUP"}, manager}, users, FindingExternalOwnersOnly, []string{OwnerExternal, OwnerExternal}},
+		{"inactive and external owners", []*directory.Member{owner("partner@other.org"), owner("bob@example.com")}, users, FindingInactiveOrExternalOwners, []string{OwnerExternal, OwnerSuspended}},
+		{"active owner among inactive and external owners", []*directory.Member{owner("bob@example.com"), owner("partner@other.org"), owner("Alice@example.com")}, users, "", nil},
+		{"unknown owner without the users", []*directory.Member{owner("gone@example.com")}, nil, "", nil},
+	} {
+		orphaned := orphanedGroup(&directory.Group{Email: "group@example.com", DirectMembersCount: int64(len(test.members))}, test.members, test.users, domains)
+		if test.finding == "" {
+			if orphaned != nil {
+				t.Errorf("%s: orphanedGroup = %+v, want nil", test.name, orphaned)
+			}
+			continue
+		}
+		if orphaned == nil {
+			t.Errorf("%s: orphanedGroup = nil, want %s", test.name, test.finding)
+			continue
+		}
+		if orphaned.Finding != test.finding || !reflect.DeepEqual(orphaned.OwnerStatuses, test.statuses) {
+			t.Errorf("%s: orphanedGroup = %s %v, want %s %v", test.name, orphaned.Finding, orphaned.OwnerStatuses, test.finding, test.statuses)
+		}
+	}
+}
+
+func TestOrphanedGroupManagers(t *testing.T) {
+	members := []*directory.Member{
+		{Email: "partner@other.org", Role: "OWNER", Type: "USER"},
+		{Email: "carol@example.com", Role: "MANAGER", Type: "USER"},
+		{Email: "erin@example.com", Role: "MEMBER", Type: "USER"},
+	}
+	orphaned := orphanedGroup(&directory.Group{Email: "group@example.com", DirectMembersCount: 3}, members, nil, Audit.NewDomains("example.com"))
+	want := &OrphanedGroup{Email: "group@example.com", Finding: FindingExternalOwnersOnly, MembersCount: 3,
+		Owners: []string{"partner@other.org"}, OwnerStatuses: []string{OwnerExternal}, Managers: []string{"carol@example.com"}}
+	if !reflect.DeepEqual(orphaned, want) {
+		t.Errorf("orphanedGroup = %+v, want %+v", orphaned, want)
+	}
+}
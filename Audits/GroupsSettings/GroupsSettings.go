@@ -0,0 +1,197 @@
+package GroupsSettings
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/groupssettings/v1"
+	"log"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Risks flagged on the settings of a group
+const (
+	RiskPublicJoin       = "public_join"       // Anyone on the internet can join the group
+	RiskExternalPosting  = "external_posting"  // Anyone on the internet can post to the group
+	RiskExternalMembers  = "external_members"  // Members outside the organization are allowed
+	RiskPublicView       = "public_view"       // Anyone on the internet can read the conversations
+	RiskPublicMembership = "public_membership" // Anyone on the internet can view the members
+)
+
+// Auditor This is the audit of the joining, posting and visibility settings of every group
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "groups-settings"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Who can join, post to and view every group, flagging the public and external ones"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryGroupReadonlyScope,
+		groupssettings.AppsGroupsSettingsScope,
+	}
+}
+
+// GroupSettings This is the security relevant settings of a group
+type GroupSettings struct {
+	Email                  string   `json:"email"`
+	WhoCanJoin             string   `json:"who_can_join"`
+	WhoCanPostMessage      string   `json:"who_can_post_message"`
+	WhoCanViewMembership   string   `json:"who_can_view_membership"`
+	WhoCanViewGroup        string   `json:"who_can_view_group"`
+	WhoCanDiscoverGroup    string   `json:"who_can_discover_group"`
+	WhoCanContactOwner     string   `json:"who_can_contact_owner"`
+	AllowExternalMembers   bool     `json:"allow_external_members"`
+	AllowWebPosting        bool     `json:"allow_web_posting"`
+	IsArchived             bool     `json:"is_archived"`
+	ArchiveOnly            bool     `json:"archive_only"`
+	MessageModerationLevel string   `json:"message_moderation_level"`
+	IncludeInGlobalAddress bool     `json:"include_in_global_address_list"`
+	Risks                  []string `json:"risks"`
+}
+
+// NewGroupSettings returns the settings of a group and flags its risks
+func NewGroupSettings(email string, settings *groupssettings.Groups) *GroupSettings {
+	groupSettings := &GroupSettings{
+		Email:                  email,
+		WhoCanJoin:             settings.WhoCanJoin,
+		WhoCanPostMessage:      settings.WhoCanPostMessage,
+		WhoCanViewMembership:   settings.WhoCanViewMembership,
+		WhoCanViewGroup:        settings.WhoCanViewGroup,
+		WhoCanDiscoverGroup:    settings.WhoCanDiscoverGroup,
+		WhoCanContactOwner:     settings.WhoCanContactOwner,
+		AllowExternalMembers:   settings.AllowExternalMembers == "true",
+		AllowWebPosting:        settings.AllowWebPosting == "true",
+		IsArchived:             settings.IsArchived == "true",
+		ArchiveOnly:            settings.ArchiveOnly == "true",
+		MessageModerationLevel: settings.MessageModerationLevel,
+		IncludeInGlobalAddress: settings.IncludeInGlobalAddressList == "true",
+	}
+	if settings.WhoCanJoin == "ANYONE_CAN_JOIN" {
+		groupSettings.Risks = append(groupSettings.Risks, RiskPublicJoin)
+	}
+	// An archive only group rejects every message whatever the posting setting
+	if settings.WhoCanPostMessage == "ANYONE_CAN_POST" && !groupSettings.ArchiveOnly {
+		groupSettings.Risks = append(groupSettings.Risks, RiskExternalPosting)
+	}
+	if groupSettings.AllowExternalMembers {
+		groupSettings.Risks = append(groupSettings.Risks, RiskExternalMembers)
+	}
+	if settings.WhoCanViewGroup == "ANYONE_CAN_VIEW" {
+		groupSettings.Risks = append(groupSettings.Risks, RiskPublicView)
+	}
+	if settings.WhoCanViewMembership == "ANYONE_CAN_VIEW" {
+		groupSettings.Risks = append(groupSettings.Risks, RiskPublicMembership)
+	}
+	return groupSettings
+}
+
+// PubliclyJoinable returns true when anyone on the internet can join the group
+func (receiver *GroupSettings) PubliclyJoinable() bool {
+	return receiver.hasRisk(RiskPublicJoin)
+}
+
+// ExternallyPostable returns true when anyone on the internet can post to the group
+func (receiver *GroupSettings) ExternallyPostable() bool {
+	return receiver.hasRisk(RiskExternalPosting)
+}
+
+// hasRisk returns true when the risk was flagged
+func (receiver *GroupSettings) hasRisk(risk string) bool {
+	for _, flagged := range receiver.Risks {
+		if flagged == risk {
+			return true
+		}
+	}
+	return false
+}
+
+// Row returns the row of the group in the groupsSettings report
+func (receiver *GroupSettings) Row() []any {
+	return []any{
+		receiver.Email,
+		receiver.WhoCanJoin,
+		receiver.WhoCanPostMessage,
+		receiver.WhoCanViewMembership,
+		receiver.WhoCanViewGroup,
+		receiver.WhoCanDiscoverGroup,
+		receiver.WhoCanContactOwner,
+		receiver.AllowExternalMembers,
+		receiver.AllowWebPosting,
+		receiver.IsArchived,
+		receiver.ArchiveOnly,
+		receiver.MessageModerationLevel,
+		receiver.IncludeInGlobalAddress,
+		receiver.PubliclyJoinable(),
+		receiver.ExternallyPostable(),
+		receiver.Risks,
+	}
+}
+
+// Run gets the settings of every group
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+	groupsSettingsAPI := clients.GroupsSettings()
+
+	// Get all the groups of the domain, resuming the listing of the previous run
+	log.Println("Getting all groups...")
+	allGroups, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "groups", func(pageToken string) ([]*directory.Group, string, error) {
+		return directoryAPI.QueryGroupsPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Total of %d groups found...", len(allGroups))
+
+	// Get the settings of every group with the worker pool, skipping the groups of the previous run
+	results := Checkpoint.Run(ctx, clients.Checkpoint, receiver.Name(), "settings", allGroups, func(group *directory.Group) string {
+		return group.Email
+	}, WorkerPool.Options{
+		Concurrency: clients.Concurrency,
+		Progress:    WorkerPool.LogProgress("Groups Settings", 100),
+	}, func(ctx context.Context, group *directory.Group) (*GroupSettings, error) {
+		settings, err := groupsSettingsAPI.GetGroupSettings(group.Email)
+		if err != nil {
+			return nil, Audit.Call("GroupsSettingsAPI.GetGroupSettings", err)
+		}
+		return NewGroupSettings(group.Email, settings), nil
+	})
+
+	// Record the groups that failed, they are left out of the csv
+	Audit.RecordFailures(ctx, clients.Errors, receiver.Name(), "group", allGroups, func(group *directory.Group) string {
+		return group.Email
+	}, results)
+
+	var csvRows [][]any
+	flagged := 0
+	for _, settings := range WorkerPool.Values(results) {
+		csvRows = append(csvRows, settings.Row())
+		if len(settings.Risks) > 0 {
+			flagged++
+		}
+	}
+	log.Printf("%d of %d groups have public or external settings", flagged, len(csvRows))
+
+	headers := []string{"GROUP_EMAIL", "WHO_CAN_JOIN", "WHO_CAN_POST_MESSAGE", "WHO_CAN_VIEW_MEMBERSHIP", "WHO_CAN_VIEW_GROUP",
+		"WHO_CAN_DISCOVER_GROUP", "WHO_CAN_CONTACT_OWNER", "ALLOW_EXTERNAL_MEMBERS", "ALLOW_WEB_POSTING", "IS_ARCHIVED", "ARCHIVE_ONLY",
+		"MESSAGE_MODERATION_LEVEL", "INCLUDE_IN_GLOBAL_ADDRESS_LIST", "PUBLICLY_JOINABLE", "EXTERNALLY_POSTABLE", "RISKS"}
+
+	// Write the groups collected so far when the context is done
+	if err := Report.WriteAll(sink, "groupsSettings", headers, csvRows); err != nil {
+		return err
+	}
+	return WorkerPool.Incomplete(ctx, "groups", results)
+}
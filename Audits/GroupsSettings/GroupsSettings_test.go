@@ -0,0 +1,61 @@
+package GroupsSettings
+
+import (
+	"google.golang.org/api/groupssettings/v1"
+	"reflect"
+	"testing"
+)
+
+func TestNewGroupSettings(t *testing.T) {
+	// safe returns the settings of a group restricted to the members of the domain
+	safe := func() *groupssettings.Groups {
+		return &groupssettings.Groups{
+			WhoCanJoin:           "INVITED_CAN_JOIN",
+			WhoCanPostMessage:    "ALL_MEMBERS_CAN_POST",
+			WhoCanViewMembership: "ALL_MEMBERS_CAN_VIEW",
+			WhoCanViewGroup:      "ALL_MEMBERS_CAN_VIEW",
+			AllowExternalMembers: "false",
+			ArchiveOnly:          "false",
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(settings *groupssettings.Groups)
+		want   []string
+	}{
+		{"no risk", func(settings *groupssettings.Groups) {}, nil},
+		{"domain members can join", func(settings *groupssettings.Groups) { settings.WhoCanJoin = "ALL_IN_DOMAIN_CAN_JOIN" }, nil},
+		{"public join", func(settings *groupssettings.Groups) { settings.WhoCanJoin = "ANYONE_CAN_JOIN" }, []string{RiskPublicJoin}},
+		{"anyone can post", func(settings *groupssettings.Groups) { settings.WhoCanPostMessage = "ANYONE_CAN_POST" }, []string{RiskExternalPosting}},
+		{"anyone can post to an archive only group", func(settings *groupssettings.Groups) {
+			settings.WhoCanPostMessage = "ANYONE_CAN_POST"
+			settings.ArchiveOnly = "true"
+		}, nil},
+		{"external members allowed", func(settings *groupssettings.Groups) { settings.AllowExternalMembers = "true" }, []string{RiskExternalMembers}},
+		{"public view", func(settings *groupssettings.Groups) { settings.WhoCanViewGroup = "ANYONE_CAN_VIEW" }, []string{RiskPublicView}},
+		{"public membership", func(settings *groupssettings.Groups) { settings.WhoCanViewMembership = "ANYONE_CAN_VIEW" }, []string{RiskPublicMembership}},
+		{"every risk", func(settings *groupssettings.Groups) {
+			settings.WhoCanJoin = "ANYONE_CAN_JOIN"
+			settings.WhoCanPostMessage = "ANYONE_CAN_POST"
+			settings.AllowExternalMembers = "true"
+			settings.WhoCanViewGroup = "ANYONE_CAN_VIEW"
+			settings.WhoCanViewMembership = "ANYONE_CAN_VIEW"
+		}, []string{RiskPublicJoin, RiskExternalPosting, RiskExternalMembers, RiskPublicView, RiskPublicMembership}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := safe()
+			test.modify(settings)
+			got := NewGroupSettings("group@example.com", settings)
+			if !reflect.DeepEqual(got.Risks, test.want) {
+				t.Errorf("Risks = %v, want %v", got.Risks, test.want)
+			}
+			if got.PubliclyJoinable() != (settings.WhoCanJoin == "ANYONE_CAN_JOIN") {
+				t.Errorf("PubliclyJoinable = %v with WhoCanJoin %s", got.PubliclyJoinable(), settings.WhoCanJoin)
+			}
+			if postable := settings.WhoCanPostMessage == "ANYONE_CAN_POST" && settings.ArchiveOnly != "true"; got.ExternallyPostable() != postable {
+				t.Errorf("ExternallyPostable = %v, want %v", got.ExternallyPostable(), postable)
+			}
+		})
+	}
+}
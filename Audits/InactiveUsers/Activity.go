@@ -0,0 +1,81 @@
+package InactiveUsers
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"sort"
+	"time"
+)
+
+// Default thresholds of the audit
+var (
+	DefaultThresholdDays  = []int{30, 90, 180}
+	DefaultNewAccountDays = 14
+)
+
+// Statuses of the activity of a user
+const (
+	StatusActive        = "active"          // Logged in within the smallest threshold
+	StatusInactive      = "inactive"        // Did not log in for at least the smallest threshold
+	StatusNeverLoggedIn = "never_logged_in" // Never logged in and older than the new account period
+	StatusNewAccount    = "new_account"     // Never logged in but created within the new account period
+)
+
+// Activity This is the login activity of a user at the time of the audit
+type Activity struct {
+	User   *GoogleAPI.GoogleUser
+	Status string
+	// ThresholdDays is the largest threshold exceeded by an inactive user, zero for the other statuses
+	ThresholdDays int
+	// DaysSinceLogin is the number of days since the last login, -1 when the user never logged in
+	DaysSinceLogin int
+	// DaysSinceCreation is the age of the account in days, -1 when the creation time is unknown
+	DaysSinceCreation int
+}
+
+// NewActivity returns the activity of a user at the given time against the sorted thresholds
+func NewActivity(user *GoogleAPI.GoogleUser, now time.Time, thresholdDays []int, newAccountDays int) *Activity {
+	activity := &Activity{User: user, DaysSinceLogin: -1, DaysSinceCreation: -1}
+	if created := user.Created(); !created.IsZero() {
+		activity.DaysSinceCreation = days(now.Sub(created))
+	}
+
+	lastLogin, loggedIn := user.LastLogin()
+	if !loggedIn {
+		// An account never used may simply not be handed over yet
+		activity.Status = StatusNeverLoggedIn
+		if activity.DaysSinceCreation >= 0 && activity.DaysSinceCreation < newAccountDays {
+			activity.Status = StatusNewAccount
+		}
+		return activity
+	}
+
+	activity.DaysSinceLogin = days(now.Sub(lastLogin))
+	activity.Status = StatusActive
+	for _, threshold := range thresholdDays {
+		if activity.DaysSinceLogin >= threshold {
+			activity.Status = StatusInactive
+			activity.ThresholdDays = threshold
+		}
+	}
+	return activity
+}
+
+// Dormant returns true when the account is inactive or was never used after the new account period
+func (receiver *Activity) Dormant() bool {
+	return receiver.Status == StatusInactive || receiver.Status == StatusNeverLoggedIn
+}
+
+// thresholds returns the sorted thresholds, the defaults when none is given
+func thresholds(thresholdDays []int) []int {
+	if len(thresholdDays) == 0 {
+		thresholdDays = DefaultThresholdDays
+	}
+	sorted := append([]int{}, thresholdDays...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// days returns the number of whole days of a duration
+func days(duration time.Duration) int {
+	return int(duration / (24 * time.Hour))
+}
@@ -0,0 +1,66 @@
+package InactiveUsers
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewActivity(t *testing.T) {
+	now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+	// daysAgo returns the RFC 3339 time of the given number of days before now
+	daysAgo := func(days int) string {
+		return now.AddDate(0, 0, -days).Format(time.RFC3339)
+	}
+	never := "1970-01-01T00:00:00.000Z"
+
+	tests := []struct {
+		name              string
+		lastLogin         string
+		created           string
+		wantStatus        string
+		wantThreshold     int
+		wantSinceLogin    int
+		wantSinceCreation int
+	}{
+		{"logged in today", daysAgo(0), daysAgo(400), StatusActive, 0, 0, 400},
+		{"just under the smallest threshold", daysAgo(29), daysAgo(400), StatusActive, 0, 29, 400},
+		{"at the smallest threshold", daysAgo(30), daysAgo(400), StatusInactive, 30, 30, 400},
+		{"between two thresholds", daysAgo(120), daysAgo(400), StatusInactive, 90, 120, 400},
+		{"beyond the largest threshold", daysAgo(365), daysAgo(400), StatusInactive, 180, 365, 400},
+		{"never logged in", never, daysAgo(400), StatusNeverLoggedIn, 0, -1, 400},
+		{"never logged in without a login time", "", daysAgo(400), StatusNeverLoggedIn, 0, -1, 400},
+		{"never logged in within the new account period", never, daysAgo(13), StatusNewAccount, 0, -1, 13},
+		{"never logged in at the end of the new account period", never, daysAgo(14), StatusNeverLoggedIn, 0, -1, 14},
+		{"never logged in with an unknown creation time", never, "", StatusNeverLoggedIn, 0, -1, -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := &GoogleAPI.GoogleUser{LastLoginTime: test.lastLogin, CreationTime: test.created}
+			got := NewActivity(user, now, []int{30, 90, 180}, 14)
+			want := &Activity{User: user, Status: test.wantStatus, ThresholdDays: test.wantThreshold,
+				DaysSinceLogin: test.wantSinceLogin, DaysSinceCreation: test.wantSinceCreation}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("NewActivity = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestActivityDormant(t *testing.T) {
+	for status, want := range map[string]bool{StatusActive: false, StatusInactive: true, StatusNeverLoggedIn: true, StatusNewAccount: false} {
+		if got := (&Activity{Status: status}).Dormant(); got != want {
+			t.Errorf("Dormant of %s = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	if got := thresholds([]int{180, 30, 90}); !reflect.DeepEqual(got, []int{30, 90, 180}) {
+		t.Errorf("thresholds = %v, want them sorted", got)
+	}
+	if got := thresholds(nil); !reflect.DeepEqual(got, DefaultThresholdDays) {
+		t.Errorf("thresholds(nil) = %v, want the defaults", got)
+	}
+}
@@ -0,0 +1,179 @@
+package InactiveUsers
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	directory "google.golang.org/api/admin/directory/v1"
+	"log"
+	"time"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Auditor This is the audit of the users that stopped logging in or never logged in
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "inactive-users"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Dormant and never used accounts with their admin status and OAuth tokens"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryUserReadonlyScope,
+		directory.AdminDirectoryUserSecurityScope,
+	}
+}
+
+// Run classifies the activity of every user and gets the tokens of the dormant ones
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+	thresholdDays := thresholds(clients.InactiveDays)
+	newAccountDays := clients.NewAccountDays
+	if newAccountDays == 0 {
+		newAccountDays = DefaultNewAccountDays
+	}
+
+	// Get all the users of the domain, resuming the listing of the previous run
+	log.Println("Getting all users...")
+	allUsers, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	allUsers = clients.FilterUsers(allUsers)
+	log.Printf("Total of %d users found...", len(allUsers))
+
+	// Classify the activity of every user, the new accounts are only counted in the summary
+	now := time.Now()
+	var activities, dormant []*Activity
+	for _, user := range allUsers {
+		activity := NewActivity(GoogleAPI.NewGoogleUser(user), now, thresholdDays, newAccountDays)
+		activities = append(activities, activity)
+		if activity.Dormant() {
+			dormant = append(dormant, activity)
+		}
+	}
+	log.Printf("%d users did not log in for %d days or never logged in", len(dormant), thresholdDays[0])
+
+	// Get the tokens of the dormant users only, an unused account with access granted to apps is the riskiest
+	results := Checkpoint.Run(ctx, clients.Checkpoint, receiver.Name(), "tokens", dormant, func(activity *Activity) string {
+		return activity.User.PrimaryEmail
+	}, WorkerPool.Options{
+		Concurrency: clients.Concurrency,
+		Progress:    WorkerPool.LogProgress("Dormant Users Tokens", 100),
+	}, func(ctx context.Context, activity *Activity) ([]*directory.Token, error) {
+		tokens, err := directoryAPI.GetUserTokens(activity.User.PrimaryEmail)
+		if err != nil {
+			return nil, Audit.Call("DirectoryAPI.GetUserTokens", err)
+		}
+		if tokens == nil {
+			tokens = []*directory.Token{}
+		}
+		return tokens, nil
+	})
+	Audit.RecordFailures(ctx, clients.Errors, receiver.Name(), "user", dormant, func(activity *Activity) string {
+		return activity.User.PrimaryEmail
+	}, results)
+
+	// Write the dormant users, the users whose tokens could not be listed are kept with empty token columns
+	var csvRows [][]any
+	for i, activity := range dormant {
+		user := activity.User
+		var tokenCount any
+		var apps []string
+		if results[i].Err == nil {
+			tokenCount = len(results[i].Value)
+			for _, token := range results[i].Value {
+				apps = append(apps, token.DisplayText)
+			}
+		}
+		csvRows = append(csvRows, []any{
+			user.PrimaryEmail,
+			user.OrgUnitPath,
+			activity.Status,
+			activity.ThresholdDays,
+			activity.DaysSinceLogin,
+			user.LastLoginTime,
+			user.CreationTime,
+			activity.DaysSinceCreation,
+			user.IsAdmin,
+			user.IsDelegatedAdmin,
+			user.Suspended,
+			user.Archived,
+			tokenCount,
+			apps,
+		})
+	}
+	headers := []string{"PRIMARY_EMAIL", "ORG_UNIT_PATH", "STATUS", "INACTIVE_THRESHOLD_DAYS", "DAYS_SINCE_LOGIN", "LAST_LOGIN_TIME",
+		"CREATION_TIME", "DAYS_SINCE_CREATION", "IS_ADMIN", "IS_DELEGATED_ADMIN", "IS_SUSPENDED", "IS_ARCHIVED", "TOKEN_COUNT", "TOKEN_APPS"}
+	if err := Report.WriteAll(sink, "dormantAccounts", headers, csvRows); err != nil {
+		return err
+	}
+
+	if err := writeSummary(sink, activities, dormant, results, thresholdDays); err != nil {
+		return err
+	}
+	return WorkerPool.Incomplete(ctx, "dormant users", results)
+}
+
+// writeSummary writes the number of users, admins and users with tokens of every status and threshold
+func writeSummary(sink Report.Sink, activities, dormant []*Activity, results []WorkerPool.Result[[]*directory.Token], thresholdDays []int) error {
+	type bucket struct {
+		status    string
+		threshold int
+	}
+	type counts struct {
+		users, admins, withTokens int
+	}
+
+	// Count the tokens of the dormant users
+	withTokens := make(map[*Activity]bool)
+	for i, activity := range dormant {
+		withTokens[activity] = results[i].Err == nil && len(results[i].Value) > 0
+	}
+	buckets := make(map[bucket]*counts)
+	for _, activity := range activities {
+		key := bucket{activity.Status, activity.ThresholdDays}
+		if buckets[key] == nil {
+			buckets[key] = &counts{}
+		}
+		buckets[key].users++
+		if activity.User.IsAdmin || activity.User.IsDelegatedAdmin {
+			buckets[key].admins++
+		}
+		if withTokens[activity] {
+			buckets[key].withTokens++
+		}
+	}
+
+	// Write the statuses from the most to the least dormant
+	keys := []bucket{{StatusNeverLoggedIn, 0}}
+	for i := len(thresholdDays) - 1; i >= 0; i-- {
+		keys = append(keys, bucket{StatusInactive, thresholdDays[i]})
+	}
+	keys = append(keys, bucket{StatusNewAccount, 0}, bucket{StatusActive, 0})
+	var csvRows [][]any
+	for _, key := range keys {
+		count := buckets[key]
+		if count == nil {
+			count = &counts{}
+		}
+		csvRows = append(csvRows, []any{key.status, key.threshold, count.users, count.admins, count.withTokens})
+	}
+	headers := []string{"STATUS", "INACTIVE_THRESHOLD_DAYS", "USERS", "ADMINS", "WITH_TOKENS"}
+	return Report.WriteAll(sink, "inactivitySummary", headers, csvRows)
+}
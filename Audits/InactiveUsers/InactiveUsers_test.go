@@ -0,0 +1,56 @@
+package InactiveUsers
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/FakeGoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	directory "google.golang.org/api/admin/directory/v1"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunLeavesTheNewAccountsOutOfTheDormantReport(t *testing.T) {
+	now := time.Now()
+	// daysAgo returns the RFC 3339 time of the given number of days before now
+	daysAgo := func(days int) string {
+		return now.AddDate(0, 0, -days).Format(time.RFC3339)
+	}
+	never := "1970-01-01T00:00:00.000Z"
+	server := FakeGoogleAPI.NewServer(&FakeGoogleAPI.Tenant{
+		Users: []*directory.User{
+			{Id: "1", PrimaryEmail: "active@example.com", OrgUnitPath: "/", LastLoginTime: daysAgo(1), CreationTime: daysAgo(400)},
+			{Id: "2", PrimaryEmail: "dormant@example.com", OrgUnitPath: "/", LastLoginTime: never, CreationTime: daysAgo(400)},
+			{Id: "3", PrimaryEmail: "new@example.com", OrgUnitPath: "/", LastLoginTime: never, CreationTime: daysAgo(2)},
+		},
+	})
+	defer server.Close()
+	clients := Audit.NewClients(server.Client(), nil, context.Background())
+	dir := t.TempDir()
+
+	if err := (&Auditor{}).Run(context.Background(), clients, Report.NewCSVSink(dir)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	dormant := readFile(t, filepath.Join(dir, "dormantAccounts.csv"))
+	if !strings.Contains(dormant, "dormant@example.com") {
+		t.Errorf("dormantAccounts.csv = %q, want dormant@example.com", dormant)
+	}
+	if strings.Contains(dormant, "new@example.com") || strings.Contains(dormant, "active@example.com") {
+		t.Errorf("dormantAccounts.csv = %q, want neither the new nor the active account", dormant)
+	}
+	if summary := readFile(t, filepath.Join(dir, "inactivitySummary.csv")); !strings.Contains(summary, StatusNewAccount+",0,1,0,0") {
+		t.Errorf("inactivitySummary.csv = %q, want the new account counted in its status", summary)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile %s: %v", path, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,256 @@
+package Inventory
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// auditName This is the name of the audit, it also names its progress in the checkpoint
+const auditName = "inventory"
+
+// Auditor This is the audit that inventories the projects, service accounts, users, groups, domains and resources
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return auditName
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Inventory of Google Cloud projects, service accounts, users, groups, domains, buildings and calendar resources"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return append([]string{cloudresourcemanager.CloudPlatformScope}, GoogleAPI.AdminScopes...)
+}
+
+// Run gets all the projects, service accounts, users, groups, domains, buildings and calendar resources
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+
+	// Collect the errors of the goroutines
+	errs := make(chan error, 5)
+
+	// Start a wait group to wait for all the goroutines to finish $$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$
+	wg := &sync.WaitGroup{}
+	// Add 5 to the wait group
+	wg.Add(5)
+
+	// Start the goroutine to get all the projects ---------------------------------------------------------------------
+	go func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		timer := time.Now()
+		log.Printf("Getting all projects...")
+
+		// Write every project as soon as its service accounts are listed, while the next pages of projects are fetched
+		headers := []string{"project_id", "project_number", "project_name", "service_accounts"}
+		count, err := writeStream(ctx, sink, "projects", headers, StreamGoogleCloudProjects(ctx, clients), func(project *GoogleCloudProject) []any {
+			return []any{project.Id, project.Number, project.Name, project.ServiceAccounts}
+		})
+		clients.Errors.Processed(count)
+		if err != nil && !WorkerPool.IsIncomplete(err) {
+			log.Printf("Error getting all projects: %s", err.Error())
+		}
+		log.Println("Time to get all projects: " + time.Since(timer).String())
+		errs <- err
+	}(wg)
+
+	// Start the goroutine to get all the users ------------------------------------------------------------------------
+	go func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		timer := time.Now()
+		log.Printf("Getting all users...")
+
+		// Write every user as soon as its tokens are listed, while the next pages of users are fetched
+		headers := []string{"user_id", "primary_email", "archived", "is_admin", "is_delegated_admin", "is_suspended", "last_login_time", "is_mailbox_setup", "org_unit_path", "notes"}
+		count, err := writeStream(directoryAPI.Ctx, sink, "users", headers, directoryAPI.StreamUsersAndToken(""), func(user *GoogleAPI.GoogleUser) []any {
+			if !clients.InOrgUnit(user.OrgUnitPath) {
+				return nil
+			}
+			if user.TokensErr != nil {
+				clients.Errors.Add(auditName, "user", user.PrimaryEmail, Audit.Call("DirectoryAPI.GetUserTokens", user.TokensErr))
+			}
+			return []any{
+				user.Id,
+				user.PrimaryEmail,
+				user.Archived,
+				user.IsAdmin,
+				user.IsDelegatedAdmin,
+				user.Suspended,
+				user.LastLoginTime,
+				user.IsMailboxSetup,
+				user.OrgUnitPath,
+				user.Tokens}
+		})
+		clients.Errors.Processed(count)
+		if err != nil && !WorkerPool.IsIncomplete(err) {
+			log.Printf("Error getting users: %s", err.Error())
+		}
+		log.Printf("Time to get users: %s", time.Since(timer).String())
+		errs <- err
+	}(wg)
+
+	// Start the goroutine to get all the groups -----------------------------------------------------------------------
+	go func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		timer := time.Now()
+		log.Printf("Getting all groups...")
+
+		// Write the groups page by page
+		headers := []string{"email", "name", "member_count", "admin_created"}
+		_, err := writeStream(directoryAPI.Ctx, sink, "groups", headers, directoryAPI.StreamGroups(""), func(group *directory.Group) []any {
+			return []any{
+				group.Email,
+				group.Name,
+				group.DirectMembersCount,
+				group.AdminCreated}
+		})
+		if err != nil && !WorkerPool.IsIncomplete(err) {
+			log.Println("Error getting groups: " + err.Error())
+		}
+		log.Printf("Time to get groups: %s", time.Since(timer).String())
+		errs <- err
+	}(wg)
+
+	// Start the goroutine to get the domains and domain aliases ------------------------------------------------------
+	go func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		log.Printf("Getting the domains...")
+		errs <- writeDomains(sink, directoryAPI)
+	}(wg)
+
+	// Start the goroutine to get the buildings and calendar resources ------------------------------------------------
+	go func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		log.Printf("Getting the buildings and calendar resources...")
+		errs <- writeResources(sink, directoryAPI)
+	}(wg)
+	// Wait for all the goroutines to finish
+	wg.Wait()
+	// Wait for all the goroutines to finish $$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$$
+	close(errs)
+
+	// Return the first error of the goroutines
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDomains writes the domains and the domain aliases of the customer
+func writeDomains(sink Report.Sink, directoryAPI *GoogleAPI.DirectoryAPI) error {
+	domains, err := directoryAPI.GetDomains()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetDomains", err)
+	}
+	aliases, err := directoryAPI.GetDomainAliases()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetDomainAliases", err)
+	}
+
+	var records [][]any
+	for _, domain := range domains {
+		domainType := "secondary"
+		if domain.IsPrimary {
+			domainType = "primary"
+		}
+		records = append(records, []any{domain.DomainName, domainType, "", domain.Verified, Report.FormatMillis(domain.CreationTime)})
+	}
+	for _, alias := range aliases {
+		records = append(records, []any{alias.DomainAliasName, "alias", alias.ParentDomainName, alias.Verified, Report.FormatMillis(alias.CreationTime)})
+	}
+	headers := []string{"domain_name", "domain_type", "parent_domain_name", "verified", "creation_time"}
+	return Report.WriteAll(sink, "domains", headers, records)
+}
+
+// writeResources writes the buildings and the calendar resources of the customer
+func writeResources(sink Report.Sink, directoryAPI *GoogleAPI.DirectoryAPI) error {
+	buildings, err := directoryAPI.GetBuildings()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetBuildings", err)
+	}
+	var records [][]any
+	for _, building := range buildings {
+		var address []string
+		if building.Address != nil {
+			address = append(address, building.Address.AddressLines...)
+			for _, part := range []string{building.Address.Locality, building.Address.AdministrativeArea, building.Address.PostalCode, building.Address.RegionCode} {
+				if part != "" {
+					address = append(address, part)
+				}
+			}
+		}
+		records = append(records, []any{building.BuildingId, building.BuildingName, building.Description, strings.Join(address, ", "), building.FloorNames})
+	}
+	headers := []string{"building_id", "building_name", "description", "address", "floor_names"}
+	if err := Report.WriteAll(sink, "buildings", headers, records); err != nil {
+		return err
+	}
+
+	resources, err := directoryAPI.GetCalendarResources()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetCalendarResources", err)
+	}
+	records = nil
+	for _, resource := range resources {
+		records = append(records, []any{
+			resource.ResourceId,
+			resource.ResourceName,
+			resource.ResourceEmail,
+			resource.ResourceCategory,
+			resource.ResourceType,
+			resource.BuildingId,
+			resource.FloorName,
+			resource.Capacity})
+	}
+	headers = []string{"resource_id", "resource_name", "resource_email", "resource_category", "resource_type", "building_id", "floor_name", "capacity"}
+	return Report.WriteAll(sink, "calendarResources", headers, records)
+}
+
+// writeStream writes a row per item of the stream as soon as it is received, the items whose row is nil are skipped.
+// It reads the stream to its end and returns the number of items and the first error, of the stream or of the report,
+// or an IncompleteError when the context is done since the streams are closed without an error once it is.
+func writeStream[T any](ctx context.Context, sink Report.Sink, name string, headers []string, items <-chan GoogleAPI.Item[T], row func(value T) []any) (int, error) {
+	writer, err := sink.Create(name, headers)
+	count := 0
+	for item := range items {
+		if item.Err != nil {
+			if err == nil {
+				err = item.Err
+			}
+			continue
+		}
+		count++
+		if values := row(item.Value); values != nil && writer != nil {
+			if writeErr := writer.Write(values); writeErr != nil && err == nil {
+				err = writeErr
+			}
+		}
+	}
+	if writer != nil {
+		if closeErr := writer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if ctx.Err() != nil {
+		return count, WorkerPool.Stopped(ctx, name, count)
+	}
+	return count, err
+}
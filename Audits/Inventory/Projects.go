@@ -0,0 +1,79 @@
+package Inventory
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// GoogleCloudProject This is a struct that contains all the information for a Google Cloud Project
+type GoogleCloudProject struct {
+	Id              string                         `json:"id"`
+	Number          int                            `json:"number"`
+	Name            string                         `json:"name"`
+	ServiceAccounts []*GoogleAPI.GCPServiceAccount `json:"service_accounts"`
+	Notes           string                         `json:"notes"`
+}
+
+// StreamGoogleCloudProjects This function streams the Google Cloud Projects with the service accounts of every project,
+// each project is sent as soon as its service accounts are listed while the next pages of projects are fetched, see GoogleAPI.Stream.
+// The projects whose service accounts cannot be listed are kept with the error in their notes and recorded in the error log of the clients,
+// the last item holds the error of a failed page of projects.
+func StreamGoogleCloudProjects(ctx context.Context, clients *Audit.Clients) <-chan GoogleAPI.Item[*GoogleCloudProject] {
+	crmAPI := clients.CloudResourceManager()
+	iAmAPI := clients.Iam()
+	gcpProjects := make(chan GoogleAPI.Item[*GoogleCloudProject])
+
+	// Forward the projects to the worker pool, keeping the error of the pagination for the end of the stream
+	projects := make(chan *cloudresourcemanager.Project)
+	pageErrs := make(chan error, 1)
+	go func() {
+		defer close(projects)
+		for item := range crmAPI.StreamProjects() {
+			if item.Err != nil {
+				pageErrs <- item.Err
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case projects <- item.Value:
+			}
+		}
+	}()
+
+	// Get the service accounts of every project with the worker pool
+	go func() {
+		defer close(gcpProjects)
+		results := WorkerPool.Stream(ctx, projects, WorkerPool.Options{
+			Concurrency: clients.Concurrency,
+			Progress:    WorkerPool.LogProgress("Get ServiceAccounts", 100),
+		}, func(ctx context.Context, project *cloudresourcemanager.Project) (*GoogleCloudProject, error) {
+			newGCP := &GoogleCloudProject{Id: project.ProjectId,
+				Number: int(project.ProjectNumber),
+				Name:   project.Name}
+			serviceAccounts, err := iAmAPI.GetProjectServiceAccounts(project.ProjectId)
+			if err != nil && ctx.Err() != nil { // If the context is done, the project is not collected
+				return nil, err
+			} else if err != nil { // If there is an error, set the notes to the error message
+				newGCP.Notes = err.Error()
+				clients.Errors.Add(auditName, "project", project.ProjectId, Audit.Call("IamAPI.GetProjectServiceAccounts", err))
+			} else { // If there is no error, set the service accounts
+				newGCP.ServiceAccounts = serviceAccounts
+			}
+			return newGCP, nil
+		})
+		for result := range results {
+			gcpProjects <- GoogleAPI.Item[*GoogleCloudProject]{Value: result.Value, Err: result.Err}
+		}
+		// The results are closed after the projects channel, the error of the pagination is already sent
+		select {
+		case pageErr := <-pageErrs:
+			gcpProjects <- GoogleAPI.Item[*GoogleCloudProject]{Err: pageErr}
+		default:
+		}
+	}()
+	return gcpProjects
+}
@@ -0,0 +1,62 @@
+package Inventory
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/FakeGoogleAPI"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/iam/v1"
+	"net/http"
+	"testing"
+)
+
+func TestStreamGoogleCloudProjects(t *testing.T) {
+	server := FakeGoogleAPI.NewServer(&FakeGoogleAPI.Tenant{
+		Projects: []*cloudresourcemanager.Project{
+			{ProjectId: "project-a", ProjectNumber: 101, Name: "Project A"},
+			{ProjectId: "project-b", ProjectNumber: 102, Name: "Project B"},
+			{ProjectId: "project-c", ProjectNumber: 103, Name: "Project C"},
+		},
+		ServiceAccounts: map[string][]*iam.ServiceAccount{
+			"project-a": {{Email: "sa@project-a.iam.gserviceaccount.com", ProjectId: "project-a", UniqueId: "123"}},
+		},
+	})
+	defer server.Close()
+	server.PageSize = 2
+	server.InjectError("/v1/projects/project-c/serviceAccounts", http.StatusForbidden, "forbidden", -1)
+	clients := Audit.NewClients(server.Client(), nil, context.Background())
+
+	projects := make(map[string]*GoogleCloudProject)
+	for item := range StreamGoogleCloudProjects(context.Background(), clients) {
+		if item.Err != nil {
+			t.Fatalf("StreamGoogleCloudProjects: %v", item.Err)
+		}
+		projects[item.Value.Id] = item.Value
+	}
+	if len(projects) != 3 {
+		t.Fatalf("StreamGoogleCloudProjects returned %d projects, want the 3 projects of both pages", len(projects))
+	}
+	if accounts := projects["project-a"].ServiceAccounts; len(accounts) != 1 || accounts[0].UniqueId != "123" {
+		t.Errorf("project-a service accounts = %v, want sa@project-a", accounts)
+	}
+
+	// The project whose service accounts cannot be listed is kept with the error
+	if projects["project-c"].Notes == "" || len(clients.Errors.Errors()) != 1 {
+		t.Errorf("project-c notes = %q with %d errors recorded, want the error of its service accounts", projects["project-c"].Notes, len(clients.Errors.Errors()))
+	}
+}
+
+func TestStreamGoogleCloudProjectsEndsWithPageError(t *testing.T) {
+	server := FakeGoogleAPI.NewServer(&FakeGoogleAPI.Tenant{})
+	defer server.Close()
+	server.InjectError("/v1/projects", http.StatusForbidden, "forbidden", -1)
+	clients := Audit.NewClients(server.Client(), nil, context.Background())
+
+	var last error
+	for item := range StreamGoogleCloudProjects(context.Background(), clients) {
+		last = item.Err
+	}
+	if Audit.HTTPCode(last) != http.StatusForbidden {
+		t.Errorf("last item error = %v, want the 403 of the projects page", last)
+	}
+}
@@ -0,0 +1,180 @@
+package OAuthApps
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	directory "google.golang.org/api/admin/directory/v1"
+	"log"
+	"sort"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Auditor This is the inventory of the third-party apps the users granted OAuth access to
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "oauth-apps"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Third-party apps granted OAuth access by the users, ranked by the risk of their scopes"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryUserReadonlyScope,
+		directory.AdminDirectoryUserSecurityScope,
+	}
+}
+
+// App This is a third-party app and the tokens granted to it by every user
+type App struct {
+	ClientId    string
+	DisplayText string
+	NativeApp   bool
+	Anonymous   bool
+	Users       []string
+	AdminUsers  int
+	// Scopes is the sorted union of the scopes granted by the users
+	Scopes []string
+	Risk   string
+}
+
+// HighRiskScopes returns the high risk scopes granted to the app
+func (receiver *App) HighRiskScopes() []string {
+	var scopes []string
+	for _, scope := range receiver.Scopes {
+		if ScopeRisk(scope) == RiskHigh {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// Run lists the tokens of every user and aggregates them by app
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+
+	// Get all the users of the domain, resuming the listing of the previous run
+	log.Println("Getting all users...")
+	allUsers, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	allUsers = clients.FilterUsers(allUsers)
+	log.Printf("Total of %d users found...", len(allUsers))
+
+	// Get the tokens of every user with the worker pool, skipping the users of the previous run
+	results := Checkpoint.Run(ctx, clients.Checkpoint, receiver.Name(), "tokens", allUsers, func(user *directory.User) string {
+		return user.PrimaryEmail
+	}, WorkerPool.Options{
+		Concurrency: clients.Concurrency,
+		Progress:    WorkerPool.LogProgress("Users Tokens", 100),
+	}, func(ctx context.Context, user *directory.User) ([]*directory.Token, error) {
+		tokens, err := directoryAPI.GetUserTokens(user.PrimaryEmail)
+		if err != nil {
+			return nil, Audit.Call("DirectoryAPI.GetUserTokens", err)
+		}
+		return tokens, nil
+	})
+	Audit.RecordFailures(ctx, clients.Errors, receiver.Name(), "user", allUsers, func(user *directory.User) string {
+		return user.PrimaryEmail
+	}, results)
+
+	// Aggregate the tokens by client id and list the grants of every user
+	apps := make(map[string]*App)
+	scopes := make(map[string]map[string]bool)
+	var grantRows [][]any
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		user := allUsers[result.Index]
+		isAdmin := user.IsAdmin || user.IsDelegatedAdmin
+		for _, token := range result.Value {
+			app := apps[token.ClientId]
+			if app == nil {
+				app = &App{ClientId: token.ClientId, DisplayText: token.DisplayText, NativeApp: token.NativeApp, Anonymous: token.Anonymous}
+				apps[token.ClientId] = app
+				scopes[token.ClientId] = make(map[string]bool)
+			}
+			app.Users = append(app.Users, user.PrimaryEmail)
+			if isAdmin {
+				app.AdminUsers++
+			}
+			for _, scope := range token.Scopes {
+				scopes[token.ClientId][scope] = true
+			}
+			grantRows = append(grantRows, []any{token.ClientId, token.DisplayText, user.PrimaryEmail, user.OrgUnitPath, isAdmin, Risk(token.Scopes), token.Scopes})
+		}
+	}
+
+	// Rank the apps by risk tier, then by the number of admins and users who granted access
+	ranked := make([]*App, 0, len(apps))
+	for clientId, app := range apps {
+		for scope := range scopes[clientId] {
+			app.Scopes = append(app.Scopes, scope)
+		}
+		sort.Strings(app.Scopes)
+		app.Risk = Risk(app.Scopes)
+		ranked = append(ranked, app)
+	}
+	rankApps(ranked)
+
+	var csvRows [][]any
+	for i, app := range ranked {
+		csvRows = append(csvRows, []any{
+			i + 1,
+			app.ClientId,
+			app.DisplayText,
+			app.Risk,
+			app.NativeApp,
+			app.Anonymous,
+			len(app.Users),
+			app.AdminUsers,
+			app.HighRiskScopes(),
+			len(app.Scopes),
+			app.Scopes,
+		})
+	}
+	log.Printf("Total of %d third-party apps found...", len(ranked))
+	headers := []string{"RANK", "CLIENT_ID", "DISPLAY_TEXT", "RISK_TIER", "NATIVE_APP", "ANONYMOUS", "USER_COUNT", "ADMIN_USER_COUNT",
+		"HIGH_RISK_SCOPES", "SCOPE_COUNT", "SCOPES"}
+	if err := Report.WriteAll(sink, "oauthApps", headers, csvRows); err != nil {
+		return err
+	}
+
+	headers = []string{"CLIENT_ID", "DISPLAY_TEXT", "USER_EMAIL", "ORG_UNIT_PATH", "IS_ADMIN", "RISK_TIER", "SCOPES"}
+	if err := Report.WriteAll(sink, "oauthAppGrants", headers, grantRows); err != nil {
+		return err
+	}
+	return WorkerPool.Incomplete(ctx, "users", results)
+}
+
+// rankApps sorts the apps by risk tier, then by the number of admins and users who granted access, then by client id
+func rankApps(apps []*App) {
+	sort.Slice(apps, func(i, j int) bool {
+		a, b := apps[i], apps[j]
+		if riskRank(a.Risk) != riskRank(b.Risk) {
+			return riskRank(a.Risk) < riskRank(b.Risk)
+		}
+		if a.AdminUsers != b.AdminUsers {
+			return a.AdminUsers > b.AdminUsers
+		}
+		if len(a.Users) != len(b.Users) {
+			return len(a.Users) > len(b.Users)
+		}
+		return a.ClientId < b.ClientId
+	})
+}
@@ -0,0 +1,89 @@
+package OAuthApps
+
+import "strings"
+
+// Risk tiers of the OAuth scopes, from the most to the least risky
+const (
+	RiskHigh   = "high"   // Full access to mail, files, the admin APIs or Google Cloud
+	RiskMedium = "medium" // Access to other user data, like the calendar or the contacts, or limited mail and file access
+	RiskLow    = "low"    // Sign in and the files created by the app
+)
+
+// scopePrefix This is the prefix of the scopes of the Google APIs
+const scopePrefix = "https://www.googleapis.com/auth/"
+
+// highRiskScopes are the scopes granting full access to mail, files, scripts, Google Cloud or the organization settings, without the scope prefix
+var highRiskScopes = map[string]bool{
+	"https://mail.google.com/": true,
+	"gmail.modify":             true,
+	"gmail.readonly":           true,
+	"gmail.insert":             true,
+	"gmail.settings.sharing":   true,
+	"gmail.settings.basic":     true,
+	"drive":                    true,
+	"drive.readonly":           true,
+	"drive.scripts":            true,
+	"script.projects":          true,
+	"cloud-platform":           true,
+	"cloud-platform.read-only": true,
+	"apps.groups.settings":     true,
+	"ediscovery":               true,
+	"cloud-identity":           true,
+	"cloud-identity.groups":    true,
+	"cloud-identity.devices":   true,
+	"apps.alerts":              true,
+	"apps.order":               true,
+}
+
+// lowRiskScopes are the scopes of the sign in and of the files created by the app, without the scope prefix
+var lowRiskScopes = map[string]bool{
+	"openid":           true,
+	"email":            true,
+	"profile":          true,
+	"userinfo.email":   true,
+	"userinfo.profile": true,
+	"drive.file":       true,
+	"drive.appdata":    true,
+	"drive.install":    true,
+}
+
+// ScopeRisk returns the risk tier of an OAuth scope, the admin scopes are always high
+func ScopeRisk(scope string) string {
+	name := strings.TrimPrefix(scope, scopePrefix)
+	switch {
+	case strings.HasPrefix(name, "admin."):
+		return RiskHigh
+	case highRiskScopes[name]:
+		return RiskHigh
+	case lowRiskScopes[name]:
+		return RiskLow
+	default:
+		return RiskMedium
+	}
+}
+
+// Risk returns the highest risk tier of the scopes, low when there are none
+func Risk(scopes []string) string {
+	risk := RiskLow
+	for _, scope := range scopes {
+		switch ScopeRisk(scope) {
+		case RiskHigh:
+			return RiskHigh
+		case RiskMedium:
+			risk = RiskMedium
+		}
+	}
+	return risk
+}
+
+// riskRank returns the rank of a risk tier, the highest first
+func riskRank(risk string) int {
+	switch risk {
+	case RiskHigh:
+		return 0
+	case RiskMedium:
+		return 1
+	default:
+		return 2
+	}
+}
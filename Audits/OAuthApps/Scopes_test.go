@@ -0,0 +1,84 @@
+package OAuthApps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScopeRisk(t *testing.T) {
+	tests := []struct {
+		scope string
+		want  string
+	}{
+		{"https://mail.google.com/", RiskHigh},
+		{"https://www.googleapis.com/auth/gmail.readonly", RiskHigh},
+		{"https://www.googleapis.com/auth/drive", RiskHigh},
+		{"https://www.googleapis.com/auth/cloud-platform", RiskHigh},
+		{"https://www.googleapis.com/auth/admin.directory.user.readonly", RiskHigh},
+		{"https://www.googleapis.com/auth/admin.reports.audit.readonly", RiskHigh},
+		{"https://www.googleapis.com/auth/calendar", RiskMedium},
+		{"https://www.googleapis.com/auth/contacts.readonly", RiskMedium},
+		{"https://www.googleapis.com/auth/gmail.send", RiskMedium},
+		{"openid", RiskLow},
+		{"email", RiskLow},
+		{"https://www.googleapis.com/auth/userinfo.profile", RiskLow},
+		{"https://www.googleapis.com/auth/drive.file", RiskLow},
+		// The unknown scopes are neither trusted nor flagged
+		{"https://www.googleapis.com/auth/unknown.api", RiskMedium},
+		{"https://example.com/auth/custom", RiskMedium},
+		{"", RiskMedium},
+	}
+	for _, test := range tests {
+		if got := ScopeRisk(test.scope); got != test.want {
+			t.Errorf("ScopeRisk(%q) = %s, want %s", test.scope, got, test.want)
+		}
+	}
+}
+
+func TestRisk(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		want   string
+	}{
+		{"no scopes", nil, RiskLow},
+		{"sign in only", []string{"openid", "email", "profile"}, RiskLow},
+		{"a medium scope", []string{"openid", "https://www.googleapis.com/auth/calendar"}, RiskMedium},
+		{"an unknown scope", []string{"email", "https://www.googleapis.com/auth/unknown.api"}, RiskMedium},
+		{"a high scope among others", []string{"openid", "https://www.googleapis.com/auth/calendar", "https://www.googleapis.com/auth/drive"}, RiskHigh},
+	}
+	for _, test := range tests {
+		if got := Risk(test.scopes); got != test.want {
+			t.Errorf("Risk of %s = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRankApps(t *testing.T) {
+	apps := []*App{
+		{ClientId: "low", Risk: RiskLow, Users: []string{"a", "b", "c"}, AdminUsers: 3},
+		{ClientId: "medium", Risk: RiskMedium, Users: []string{"a"}},
+		{ClientId: "high-b", Risk: RiskHigh, Users: []string{"a", "b"}},
+		{ClientId: "high-admins", Risk: RiskHigh, Users: []string{"a"}, AdminUsers: 1},
+		{ClientId: "high-a", Risk: RiskHigh, Users: []string{"a", "b"}},
+		{ClientId: "high-users", Risk: RiskHigh, Users: []string{"a", "b", "c"}},
+	}
+	rankApps(apps)
+
+	var got []string
+	for _, app := range apps {
+		got = append(got, app.ClientId)
+	}
+	// By risk tier, then admins, then users, then client id
+	want := []string{"high-admins", "high-users", "high-a", "high-b", "medium", "low"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rankApps = %v, want %v", got, want)
+	}
+}
+
+func TestHighRiskScopes(t *testing.T) {
+	app := &App{Scopes: []string{"https://www.googleapis.com/auth/calendar", "https://www.googleapis.com/auth/drive", "openid"}}
+	if got := app.HighRiskScopes(); !reflect.DeepEqual(got, []string{"https://www.googleapis.com/auth/drive"}) {
+		t.Errorf("HighRiskScopes = %v, want the drive scope", got)
+	}
+}
@@ -0,0 +1,120 @@
+package OrgUnits
+
+import (
+	"context"
+	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	directory "google.golang.org/api/admin/directory/v1"
+	"log"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Auditor This is the inventory of the organizational units with the users of every subtree
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "org-units"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Organizational unit tree with the users, admins and 2SV enrollment of every subtree"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryOrgunitReadonlyScope,
+		directory.AdminDirectoryUserReadonlyScope,
+	}
+}
+
+// Totals This is the number of users of an organizational unit subtree
+type Totals struct {
+	Users     int
+	Active    int
+	Suspended int
+	Admins    int
+	// Enrolled are the active users enrolled in 2SV
+	Enrolled int
+}
+
+// NewTotals counts the users of the subtree of an organizational unit
+func NewTotals(orgUnit *GoogleAPI.OrgUnit) *Totals {
+	totals := &Totals{}
+	for _, user := range orgUnit.SubtreeUsers() {
+		totals.Users++
+		if user.Suspended || user.Archived {
+			totals.Suspended++
+			continue
+		}
+		totals.Active++
+		if user.IsAdmin || user.IsDelegatedAdmin {
+			totals.Admins++
+		}
+		if user.IsEnrolledIn2Sv {
+			totals.Enrolled++
+		}
+	}
+	return totals
+}
+
+// Run builds the organizational unit tree and writes every unit of the subtree of the run
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+
+	log.Println("Getting the organizational units...")
+	orgUnits, err := directoryAPI.GetOrgUnits()
+	if err != nil {
+		return Audit.Call("DirectoryAPI.GetOrgUnits", err)
+	}
+
+	// Get all the users of the domain, resuming the listing of the previous run
+	log.Println("Getting all users...")
+	allUsers, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	tree := GoogleAPI.NewOrgUnitTree(orgUnits, allUsers)
+	log.Printf("Total of %d organizational units and %d users found...", len(orgUnits), len(allUsers))
+
+	// Start from the org unit of the run, the root by default
+	root := tree.Root
+	if clients.OrgUnit != "" {
+		if root = tree.Find(clients.OrgUnit); root == nil {
+			return fmt.Errorf("org unit %s not found", clients.OrgUnit)
+		}
+	}
+
+	var csvRows [][]any
+	root.Walk(func(orgUnit *GoogleAPI.OrgUnit) {
+		totals := NewTotals(orgUnit)
+		csvRows = append(csvRows, []any{
+			orgUnit.Path,
+			orgUnit.Id,
+			orgUnit.Name,
+			orgUnit.ParentPath,
+			orgUnit.Depth,
+			orgUnit.BlockInheritance,
+			len(orgUnit.Children),
+			len(orgUnit.Users),
+			totals.Users,
+			totals.Active,
+			totals.Suspended,
+			totals.Admins,
+			totals.Enrolled,
+		})
+	})
+	headers := []string{"ORG_UNIT_PATH", "ORG_UNIT_ID", "NAME", "PARENT_PATH", "DEPTH", "BLOCK_INHERITANCE", "CHILD_COUNT", "DIRECT_USERS",
+		"SUBTREE_USERS", "SUBTREE_ACTIVE_USERS", "SUBTREE_SUSPENDED_USERS", "SUBTREE_ACTIVE_ADMINS", "SUBTREE_ENROLLED_IN_2SV"}
+	return Report.WriteAll(sink, "orgUnits", headers, csvRows)
+}
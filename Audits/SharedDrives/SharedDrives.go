@@ -0,0 +1,103 @@
+package SharedDrives
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"google.golang.org/api/drive/v3"
+	"log"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Auditor This is the audit that maps the permissions of every shared drive
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "shared-drives"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Permission counts and group access for every shared drive"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{drive.DriveReadonlyScope}
+}
+
+// Run counts the permissions of every shared drive by role
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	driveAPI := clients.Drive()
+
+	// Get all the shared drives, the drives collected so far are still written when the context is done
+	allDrives, incomplete := driveAPI.GetAllDrives()
+	if incomplete != nil && ctx.Err() == nil {
+		return incomplete
+	}
+	log.Printf("Found %d shared drives", len(allDrives))
+
+	// Create the groups csv
+	var csvRows [][]any
+
+	// Count the permissions of every drive, the permissions were already fetched by GetAllDrives
+	clients.Errors.Processed(len(allDrives))
+	for _, worker := range allDrives {
+		if worker.Err != nil {
+			clients.Errors.Add(receiver.Name(), "shared_drive", worker.MetaData.Id, Audit.Call("DriveAPI.GetFilePermissions", worker.Err))
+			continue
+		}
+		ownerCount := 0
+		organizerCount := 0
+		fileOrganizerCount := 0
+		writerCount := 0
+		commenterCount := 0
+		readerCount := 0
+		for _, permission := range worker.Permissions {
+			switch permission.Role {
+			case "owner":
+				ownerCount++
+			case "organizer":
+				organizerCount++
+			case "fileOrganizer":
+				fileOrganizerCount++
+			case "writer":
+				writerCount++
+			case "commenter":
+				commenterCount++
+			case "reader":
+				readerCount++
+			}
+		}
+
+		// The groups stay empty rather than an empty object when the drive is not shared with a group
+		var groups map[string]string
+		for _, group := range worker.Groups {
+			if groups == nil {
+				groups = make(map[string]string)
+			}
+			groups[group.EmailAddress] = group.Role
+		}
+
+		csvRows = append(csvRows, []any{
+			worker.MetaData.Id,
+			worker.MetaData.Name,
+			ownerCount,
+			organizerCount,
+			fileOrganizerCount,
+			writerCount,
+			commenterCount,
+			readerCount,
+			groups})
+	}
+
+	headers := []string{"DRIVE_ID", "DRIVE_NAME", "OWNER_COUNT", "ORGANIZER_COUNT", "FILE_ORGANIZER_COUNT", "WRITER_COUNT", "COMMENTER_COUNT", "READER_COUNT", "GROUPS"}
+	if err := Report.WriteAll(sink, "sharedDrivesMap", headers, csvRows); err != nil {
+		return err
+	}
+	return incomplete
+}
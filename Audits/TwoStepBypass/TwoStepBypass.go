@@ -0,0 +1,125 @@
+package TwoStepBypass
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	directory "google.golang.org/api/admin/directory/v1"
+	"log"
+	"strconv"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Auditor This is the audit of the application-specific passwords and backup verification codes, both sign in without the second step of 2SV
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "2sv-bypass"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Users with application-specific passwords or unused backup verification codes"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryUserReadonlyScope,
+		directory.AdminDirectoryUserSecurityScope,
+	}
+}
+
+// Credentials This is the application-specific passwords of a user and the number of their unused backup codes.
+// The codes themselves are never requested, the results are recorded in the checkpoint file.
+type Credentials struct {
+	ASPs              []*directory.Asp `json:"asps"`
+	VerificationCodes int              `json:"verification_codes"`
+}
+
+// Run lists the application-specific passwords and backup verification codes of every user
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+
+	// Get all the users of the domain, resuming the listing of the previous run
+	log.Println("Getting all users...")
+	allUsers, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	allUsers = clients.FilterUsers(allUsers)
+	log.Printf("Total of %d users found...", len(allUsers))
+
+	// Get the credentials of every user with the worker pool, skipping the users of the previous run
+	results := Checkpoint.Run(ctx, clients.Checkpoint, receiver.Name(), "credentials", allUsers, func(user *directory.User) string {
+		return user.PrimaryEmail
+	}, WorkerPool.Options{
+		Concurrency: clients.Concurrency,
+		Progress:    WorkerPool.LogProgress("Users Credentials", 100),
+	}, func(ctx context.Context, user *directory.User) (*Credentials, error) {
+		asps, err := directoryAPI.GetUserASPs(user.PrimaryEmail)
+		if err != nil {
+			return nil, Audit.Call("DirectoryAPI.GetUserASPs", err)
+		}
+		codes, err := directoryAPI.GetUserVerificationCodes(user.PrimaryEmail)
+		if err != nil {
+			return nil, Audit.Call("DirectoryAPI.GetUserVerificationCodes", err)
+		}
+		return &Credentials{ASPs: asps, VerificationCodes: len(codes)}, nil
+	})
+	Audit.RecordFailures(ctx, clients.Errors, receiver.Name(), "user", allUsers, func(user *directory.User) string {
+		return user.PrimaryEmail
+	}, results)
+
+	// Write a row per application-specific password and a row per user holding backup codes
+	var aspRows, codeRows [][]any
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		user := allUsers[result.Index]
+		for _, asp := range result.Value.ASPs {
+			aspRows = append(aspRows, []any{
+				user.PrimaryEmail,
+				user.OrgUnitPath,
+				user.IsAdmin,
+				user.Suspended,
+				user.IsEnrolledIn2Sv,
+				strconv.FormatInt(asp.CodeId, 10),
+				asp.Name,
+				Report.FormatMillis(asp.CreationTime),
+				Report.FormatMillis(asp.LastTimeUsed),
+			})
+		}
+		if result.Value.VerificationCodes > 0 {
+			codeRows = append(codeRows, []any{
+				user.PrimaryEmail,
+				user.OrgUnitPath,
+				user.IsAdmin,
+				user.Suspended,
+				user.IsEnrolledIn2Sv,
+				result.Value.VerificationCodes,
+			})
+		}
+	}
+	log.Printf("%d application-specific passwords and %d users with backup codes found", len(aspRows), len(codeRows))
+
+	headers := []string{"PRIMARY_EMAIL", "ORG_UNIT_PATH", "IS_ADMIN", "IS_SUSPENDED", "IS_ENROLLED_IN_2SV", "CODE_ID", "NAME",
+		"CREATION_TIME", "LAST_TIME_USED"}
+	if err := Report.WriteAll(sink, "appSpecificPasswords", headers, aspRows); err != nil {
+		return err
+	}
+	headers = []string{"PRIMARY_EMAIL", "ORG_UNIT_PATH", "IS_ADMIN", "IS_SUSPENDED", "IS_ENROLLED_IN_2SV", "UNUSED_CODE_COUNT"}
+	if err := Report.WriteAll(sink, "backupVerificationCodes", headers, codeRows); err != nil {
+		return err
+	}
+	return WorkerPool.Incomplete(ctx, "users", results)
+}
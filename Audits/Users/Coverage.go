@@ -0,0 +1,130 @@
+package Users
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"sort"
+)
+
+// Breakdowns of the 2SV coverage statistics
+const (
+	ByAll         = "all"          // Every active user
+	ByOrgUnit     = "org_unit"     // The active users of an organizational unit
+	ByAdminStatus = "admin_status" // The active super admins, delegated admins or users
+)
+
+// Admin statuses of the users
+const (
+	SuperAdmin     = "super_admin"
+	DelegatedAdmin = "delegated_admin"
+	NonAdmin       = "user"
+)
+
+// Coverage This is the 2SV enrollment and enforcement of a group of active users
+type Coverage struct {
+	Breakdown string
+	Group     string
+	Users     int
+	// Enrolled are the users enrolled in 2SV, Enforced the users required to use it
+	Enrolled int
+	Enforced int
+	// EnrolledNotEnforced are the users enrolled by choice, who can turn 2SV off
+	EnrolledNotEnforced int
+}
+
+// NewCoverage returns the 2SV coverage of every user, every org unit and every admin status.
+// The suspended and archived users cannot sign in and are left out.
+func NewCoverage(users []*GoogleAPI.GoogleUser) []*Coverage {
+	all := &Coverage{Breakdown: ByAll, Group: ByAll}
+	orgUnits := make(map[string]*Coverage)
+	adminStatuses := make(map[string]*Coverage)
+	for _, user := range users {
+		if user.Suspended || user.Archived {
+			continue
+		}
+		orgUnit := orgUnits[user.OrgUnitPath]
+		if orgUnit == nil {
+			orgUnit = &Coverage{Breakdown: ByOrgUnit, Group: user.OrgUnitPath}
+			orgUnits[user.OrgUnitPath] = orgUnit
+		}
+		adminStatus := adminStatuses[AdminStatus(user)]
+		if adminStatus == nil {
+			adminStatus = &Coverage{Breakdown: ByAdminStatus, Group: AdminStatus(user)}
+			adminStatuses[AdminStatus(user)] = adminStatus
+		}
+		for _, coverage := range []*Coverage{all, orgUnit, adminStatus} {
+			coverage.add(user)
+		}
+	}
+	return append(append([]*Coverage{all}, sorted(adminStatuses)...), sorted(orgUnits)...)
+}
+
+// AdminStatus returns SuperAdmin, DelegatedAdmin or NonAdmin
+func AdminStatus(user *GoogleAPI.GoogleUser) string {
+	switch {
+	case user.IsAdmin:
+		return SuperAdmin
+	case user.IsDelegatedAdmin:
+		return DelegatedAdmin
+	default:
+		return NonAdmin
+	}
+}
+
+// add counts a user
+func (receiver *Coverage) add(user *GoogleAPI.GoogleUser) {
+	receiver.Users++
+	if user.IsEnrolledIn2Sv {
+		receiver.Enrolled++
+		if !user.IsEnforcedIn2Sv {
+			receiver.EnrolledNotEnforced++
+		}
+	}
+	if user.IsEnforcedIn2Sv {
+		receiver.Enforced++
+	}
+}
+
+// EnrolledPercent returns the percentage of users enrolled in 2SV
+func (receiver *Coverage) EnrolledPercent() float64 {
+	return percent(receiver.Enrolled, receiver.Users)
+}
+
+// EnforcedPercent returns the percentage of users required to use 2SV
+func (receiver *Coverage) EnforcedPercent() float64 {
+	return percent(receiver.Enforced, receiver.Users)
+}
+
+// Row returns the row of the coverage in the users2svCoverage report
+func (receiver *Coverage) Row() []any {
+	return []any{
+		receiver.Breakdown,
+		receiver.Group,
+		receiver.Users,
+		receiver.Enrolled,
+		receiver.Enforced,
+		receiver.EnrolledNotEnforced,
+		receiver.Users - receiver.Enrolled,
+		receiver.EnrolledPercent(),
+		receiver.EnforcedPercent(),
+	}
+}
+
+// percent returns the percentage, truncated to one decimal, of count in total, zero when total is zero
+func percent(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count*1000/total) / 10
+}
+
+// sorted returns the coverages sorted by group
+func sorted(coverages map[string]*Coverage) []*Coverage {
+	var list []*Coverage
+	for _, coverage := range coverages {
+		list = append(list, coverage)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Group < list[j].Group
+	})
+	return list
+}
@@ -0,0 +1,77 @@
+package Users
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"reflect"
+	"testing"
+)
+
+// user returns a GoogleUser of the org unit with its admin and 2SV statuses
+func user(orgUnitPath string, isAdmin, enrolled, enforced bool) *GoogleAPI.GoogleUser {
+	return &GoogleAPI.GoogleUser{OrgUnitPath: orgUnitPath, IsAdmin: isAdmin, IsEnrolledIn2Sv: enrolled, IsEnforcedIn2Sv: enforced}
+}
+
+func TestNewCoverage(t *testing.T) {
+	suspended := user("/Sales", false, false, false)
+	suspended.Suspended = true
+	archived := user("/Sales", false, false, false)
+	archived.Archived = true
+	delegated := user("/", false, true, false)
+	delegated.IsDelegatedAdmin = true
+
+	tests := []struct {
+		name  string
+		users []*GoogleAPI.GoogleUser
+		want  []Coverage
+	}{
+		{
+			name: "no users",
+			want: []Coverage{{Breakdown: ByAll, Group: ByAll}},
+		},
+		{
+			name:  "only inactive users",
+			users: []*GoogleAPI.GoogleUser{suspended, archived},
+			want:  []Coverage{{Breakdown: ByAll, Group: ByAll}},
+		},
+		{
+			name: "grouped by org unit and admin status",
+			users: []*GoogleAPI.GoogleUser{
+				user("/Engineering", true, true, true),
+				user("/Engineering", false, true, false),
+				user("/Sales", false, false, false),
+				delegated,
+				suspended,
+			},
+			want: []Coverage{
+				{Breakdown: ByAll, Group: ByAll, Users: 4, Enrolled: 3, Enforced: 1, EnrolledNotEnforced: 2},
+				{Breakdown: ByAdminStatus, Group: DelegatedAdmin, Users: 1, Enrolled: 1, EnrolledNotEnforced: 1},
+				{Breakdown: ByAdminStatus, Group: SuperAdmin, Users: 1, Enrolled: 1, Enforced: 1},
+				{Breakdown: ByAdminStatus, Group: NonAdmin, Users: 2, Enrolled: 1, EnrolledNotEnforced: 1},
+				{Breakdown: ByOrgUnit, Group: "/", Users: 1, Enrolled: 1, EnrolledNotEnforced: 1},
+				{Breakdown: ByOrgUnit, Group: "/Engineering", Users: 2, Enrolled: 2, Enforced: 1, EnrolledNotEnforced: 1},
+				{Breakdown: ByOrgUnit, Group: "/Sales", Users: 1},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []Coverage
+			for _, coverage := range NewCoverage(test.users) {
+				got = append(got, *coverage)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("NewCoverage = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCoveragePercent(t *testing.T) {
+	coverage := &Coverage{Users: 3, Enrolled: 2, Enforced: 1}
+	if coverage.EnrolledPercent() != 66.6 || coverage.EnforcedPercent() != 33.3 {
+		t.Errorf("percents = %v and %v, want 66.6 and 33.3", coverage.EnrolledPercent(), coverage.EnforcedPercent())
+	}
+	if empty := (&Coverage{}); empty.EnrolledPercent() != 0 || empty.EnforcedPercent() != 0 {
+		t.Error("the percents of no users are not zero")
+	}
+}
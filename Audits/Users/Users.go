@@ -0,0 +1,136 @@
+package Users
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	directory "google.golang.org/api/admin/directory/v1"
+	"log"
+	"strings"
+)
+
+func init() {
+	Audit.Register(&Auditor{})
+}
+
+// Auditor This is the audit of the two-step verification and the account hygiene of every user
+type Auditor struct{}
+
+// Name returns the name of the audit
+func (receiver *Auditor) Name() string {
+	return "users"
+}
+
+// Description returns the description of the audit
+func (receiver *Auditor) Description() string {
+	return "Two-step verification and account hygiene of every user, with 2SV coverage by org unit and admin status"
+}
+
+// Scopes returns the scopes required by the audit
+func (receiver *Auditor) Scopes() []string {
+	return []string{
+		directory.AdminDirectoryUserReadonlyScope,
+		directory.AdminDirectoryDeviceMobileReadonlyScope,
+		directory.AdminDirectoryDeviceChromeosReadonlyScope,
+	}
+}
+
+// Run lists every user and writes their hygiene and the 2SV coverage
+func (receiver *Auditor) Run(ctx context.Context, clients *Audit.Clients, sink Report.Sink) error {
+	directoryAPI := clients.Directory()
+
+	// Get all the users of the domain, resuming the listing of the previous run
+	log.Println("Getting all users...")
+	allUsers, err := Checkpoint.Paginate(clients.Checkpoint, receiver.Name(), "users", func(pageToken string) ([]*directory.User, string, error) {
+		return directoryAPI.QueryUsersPage("", pageToken)
+	})
+	if err != nil {
+		return err
+	}
+	allUsers = clients.FilterUsers(allUsers)
+	log.Printf("Total of %d users found...", len(allUsers))
+	mobileDevices, chromeOSDevices := receiver.devices(clients)
+
+	var users []*GoogleAPI.GoogleUser
+	var csvRows [][]any
+	for _, user := range allUsers {
+		googleUser := GoogleAPI.NewGoogleUser(user)
+		users = append(users, googleUser)
+		csvRows = append(csvRows, []any{
+			googleUser.PrimaryEmail,
+			googleUser.OrgUnitPath,
+			googleUser.IsAdmin,
+			googleUser.IsDelegatedAdmin,
+			googleUser.Suspended,
+			googleUser.Archived,
+			googleUser.CreationTime,
+			googleUser.LastLoginTime,
+			googleUser.IsEnrolledIn2Sv,
+			googleUser.IsEnforcedIn2Sv,
+			googleUser.AgreedToTerms,
+			googleUser.ChangePasswordAtNextLogin,
+			googleUser.HasRecoveryEmail,
+			googleUser.HasRecoveryPhone,
+			deviceCount(mobileDevices, user.PrimaryEmail),
+			deviceCount(chromeOSDevices, user.PrimaryEmail),
+		})
+	}
+	headers := []string{"PRIMARY_EMAIL", "ORG_UNIT_PATH", "IS_ADMIN", "IS_DELEGATED_ADMIN", "IS_SUSPENDED", "IS_ARCHIVED", "CREATION_TIME",
+		"LAST_LOGIN_TIME", "IS_ENROLLED_IN_2SV", "IS_ENFORCED_IN_2SV", "AGREED_TO_TERMS", "CHANGE_PASSWORD_AT_NEXT_LOGIN",
+		"HAS_RECOVERY_EMAIL", "HAS_RECOVERY_PHONE", "MOBILE_DEVICES", "CHROMEOS_DEVICES"}
+	if err := Report.WriteAll(sink, "usersHygiene", headers, csvRows); err != nil {
+		return err
+	}
+
+	// Compute the 2SV coverage of the active users
+	csvRows = nil
+	for _, coverage := range NewCoverage(users) {
+		if coverage.Breakdown == ByAll {
+			log.Printf("2SV coverage: %d of %d active users enrolled (%.1f%%), %d enforced (%.1f%%)",
+				coverage.Enrolled, coverage.Users, coverage.EnrolledPercent(), coverage.Enforced, coverage.EnforcedPercent())
+		}
+		csvRows = append(csvRows, coverage.Row())
+	}
+	headers = []string{"BREAKDOWN", "GROUP", "ACTIVE_USERS", "ENROLLED", "ENFORCED", "ENROLLED_NOT_ENFORCED", "NOT_ENROLLED", "ENROLLED_PERCENT", "ENFORCED_PERCENT"}
+	return Report.WriteAll(sink, "users2svCoverage", headers, csvRows)
+}
+
+// devices returns the number of mobile and Chrome OS devices of every user by lowercase email.
+// The devices are optional in this audit: a listing that fails is logged as a warning and its counts are nil,
+// it is not an entity of the audit so it changes neither the errors nor the processed entities of the error rate.
+func (receiver *Auditor) devices(clients *Audit.Clients) (map[string]int, map[string]int) {
+	var mobileCounts, chromeOSCounts map[string]int
+	mobileDevices, err := clients.Directory().GetMobileDevices()
+	if err != nil {
+		log.Printf("Warning: the mobile devices are not counted: %s", Audit.Call("DirectoryAPI.GetMobileDevices", err).Error())
+	} else {
+		mobileCounts = make(map[string]int)
+		for _, device := range mobileDevices {
+			for _, owner := range GoogleAPI.MobileDeviceOwners(device) {
+				mobileCounts[owner]++
+			}
+		}
+	}
+	chromeOSDevices, err := clients.Directory().GetChromeOSDevices()
+	if err != nil {
+		log.Printf("Warning: the Chrome OS devices are not counted: %s", Audit.Call("DirectoryAPI.GetChromeOSDevices", err).Error())
+	} else {
+		chromeOSCounts = make(map[string]int)
+		for _, device := range chromeOSDevices {
+			for _, owner := range GoogleAPI.ChromeOSDeviceOwners(device) {
+				chromeOSCounts[owner]++
+			}
+		}
+	}
+	return mobileCounts, chromeOSCounts
+}
+
+// deviceCount returns the number of devices of the user, nil when the devices could not be listed
+func deviceCount(counts map[string]int, email string) any {
+	if counts == nil {
+		return nil
+	}
+	return counts[strings.ToLower(email)]
+}
@@ -0,0 +1,30 @@
+package Users
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/FakeGoogleAPI"
+	directory "google.golang.org/api/admin/directory/v1"
+	"net/http"
+	"testing"
+)
+
+func TestDevicesLeaveTheErrorRateUnaffected(t *testing.T) {
+	server := FakeGoogleAPI.NewServer(&FakeGoogleAPI.Tenant{
+		ChromeOSDevices: []*directory.ChromeOsDevice{{DeviceId: "c1", AnnotatedUser: "Alice@example.com"}},
+	})
+	defer server.Close()
+	server.InjectError("/admin/directory/v1/customer/my_customer/devices/mobile", http.StatusForbidden, "forbidden", -1)
+	clients := Audit.NewClients(server.Client(), nil, context.Background())
+
+	mobileCounts, chromeOSCounts := (&Auditor{}).devices(clients)
+	if mobileCounts != nil {
+		t.Errorf("mobile counts = %v, want nil when the listing fails", mobileCounts)
+	}
+	if chromeOSCounts["alice@example.com"] != 1 {
+		t.Errorf("Chrome OS counts = %v, want a device for alice@example.com", chromeOSCounts)
+	}
+	if rate := clients.Errors.Rate(); rate != 0 || len(clients.Errors.Errors()) != 0 {
+		t.Errorf("Rate = %v with %d errors, want the failed listing to leave the error rate unaffected", rate, len(clients.Errors.Errors()))
+	}
+}
@@ -0,0 +1,194 @@
+package Checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName This is the name of the checkpoint file in the reports directory
+const FileName = "checkpoint.json"
+
+// Checkpoint This is the progress of the audits of a run, saved periodically to the reports directory so a later run can resume it.
+// Every method can be called on a nil Checkpoint, which records nothing.
+type Checkpoint struct {
+	// Interval is the minimum time between two saves triggered by the progress of the audits
+	Interval time.Duration
+
+	path     string
+	mu       sync.Mutex
+	state    *state
+	dirty    bool
+	lastSave time.Time
+}
+
+// state This is the content of the checkpoint file
+type state struct {
+	Updated time.Time                 `json:"updated"`
+	Audits  map[string]*auditProgress `json:"audits"`
+}
+
+// auditProgress This is the progress of a single audit
+type auditProgress struct {
+	// Done is set once the audit wrote all of its reports
+	Done bool `json:"done"`
+	// Lists are the paginated listings of the audit, by name
+	Lists map[string]*listProgress `json:"lists,omitempty"`
+	// Jobs are the results of the completed jobs of the audit, by job set and job key
+	Jobs map[string]map[string]json.RawMessage `json:"jobs,omitempty"`
+	// Errors are the errors of the entities skipped by the audit and Processed the number of entities it processed, recorded once it is done
+	Errors    json.RawMessage `json:"errors,omitempty"`
+	Processed int             `json:"processed,omitempty"`
+}
+
+// listProgress This is the progress of a paginated listing
+type listProgress struct {
+	Items     []json.RawMessage `json:"items"`
+	PageToken string            `json:"page_token"`
+	Done      bool              `json:"done"`
+}
+
+// New returns an empty Checkpoint saved to the given directory
+func New(path string) *Checkpoint {
+	return &Checkpoint{
+		Interval: 30 * time.Second,
+		path:     filepath.Join(path, FileName),
+		state:    &state{Audits: make(map[string]*auditProgress)},
+	}
+}
+
+// Open loads the checkpoint saved to the given directory by a previous run
+func Open(path string) (*Checkpoint, error) {
+	checkpoint := New(path)
+	data, err := os.ReadFile(checkpoint.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, checkpoint.state); err != nil {
+		return nil, err
+	}
+	if checkpoint.state.Audits == nil {
+		checkpoint.state.Audits = make(map[string]*auditProgress)
+	}
+	return checkpoint, nil
+}
+
+// Exists returns true if the directory holds a checkpoint
+func Exists(path string) bool {
+	_, err := os.Stat(filepath.Join(path, FileName))
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// Done returns true if the audit completed in a previous run
+func (receiver *Checkpoint) Done(audit string) bool {
+	if receiver == nil {
+		return false
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	progress, ok := receiver.state.Audits[audit]
+	return ok && progress.Done
+}
+
+// MarkDone records that the audit wrote all of its reports and saves the checkpoint
+func (receiver *Checkpoint) MarkDone(audit string) error {
+	if receiver == nil {
+		return nil
+	}
+	receiver.mu.Lock()
+	progress := receiver.progress(audit)
+	progress.Done = true
+	// The results of the jobs are no longer needed once the reports are written
+	progress.Lists = nil
+	progress.Jobs = nil
+	receiver.dirty = true
+	receiver.mu.Unlock()
+	return receiver.Save()
+}
+
+// Save writes the checkpoint file if it changed since the last save
+func (receiver *Checkpoint) Save() error {
+	if receiver == nil {
+		return nil
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	return receiver.save()
+}
+
+// save writes the checkpoint file, the caller holds the lock
+func (receiver *Checkpoint) save() error {
+	if !receiver.dirty {
+		return nil
+	}
+	receiver.state.Updated = time.Now()
+	data, err := json.Marshal(receiver.state)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated checkpoint
+	temporary := receiver.path + ".tmp"
+	if err := os.WriteFile(temporary, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(temporary, receiver.path); err != nil {
+		return err
+	}
+	receiver.dirty = false
+	receiver.lastSave = time.Now()
+	return nil
+}
+
+// changed marks the checkpoint as changed and saves it when the interval elapsed, the caller holds the lock
+func (receiver *Checkpoint) changed() {
+	receiver.dirty = true
+	if time.Since(receiver.lastSave) < receiver.Interval {
+		return
+	}
+	if err := receiver.save(); err != nil {
+		log.Printf("Unable to save the checkpoint: %s", err.Error())
+	}
+}
+
+// progress returns the progress of the audit, the caller holds the lock
+func (receiver *Checkpoint) progress(audit string) *auditProgress {
+	progress, ok := receiver.state.Audits[audit]
+	if !ok {
+		progress = &auditProgress{}
+		receiver.state.Audits[audit] = progress
+	}
+	return progress
+}
+
+// list returns the progress of a listing of the audit, the caller holds the lock
+func (receiver *Checkpoint) list(audit, name string) *listProgress {
+	progress := receiver.progress(audit)
+	if progress.Lists == nil {
+		progress.Lists = make(map[string]*listProgress)
+	}
+	list, ok := progress.Lists[name]
+	if !ok {
+		list = &listProgress{}
+		progress.Lists[name] = list
+	}
+	return list
+}
+
+// jobs returns the results of a job set of the audit, the caller holds the lock
+func (receiver *Checkpoint) jobs(audit, name string) map[string]json.RawMessage {
+	progress := receiver.progress(audit)
+	if progress.Jobs == nil {
+		progress.Jobs = make(map[string]map[string]json.RawMessage)
+	}
+	jobs, ok := progress.Jobs[name]
+	if !ok {
+		jobs = make(map[string]json.RawMessage)
+		progress.Jobs[name] = jobs
+	}
+	return jobs
+}
@@ -0,0 +1,114 @@
+package Checkpoint_test
+
+import (
+	"context"
+	"errors"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	"strconv"
+	"testing"
+)
+
+func TestRunSkipsJobsOfThePreviousRun(t *testing.T) {
+	dir := t.TempDir()
+	jobs := []int{1, 2, 3, 4}
+	key := func(job int) string { return strconv.Itoa(job) }
+
+	// The first run fails on the last job
+	first := Checkpoint.New(dir)
+	results := Checkpoint.Run(context.Background(), first, "audit", "jobs", jobs, key, WorkerPool.Options{Concurrency: 2}, func(ctx context.Context, job int) ([]string, error) {
+		if job == 4 {
+			return nil, errors.New("failed")
+		}
+		return []string{key(job)}, nil
+	})
+	if len(WorkerPool.Errors(results)) != 1 {
+		t.Fatalf("first run returned %d errors, want 1", len(WorkerPool.Errors(results)))
+	}
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// The resumed run only executes the failed job
+	resumed, err := Checkpoint.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var executed []int
+	results = Checkpoint.Run(context.Background(), resumed, "audit", "jobs", jobs, key, WorkerPool.Options{Concurrency: 1}, func(ctx context.Context, job int) ([]string, error) {
+		executed = append(executed, job)
+		return []string{key(job)}, nil
+	})
+	if len(executed) != 1 || executed[0] != 4 {
+		t.Errorf("resumed run executed %v, want [4]", executed)
+	}
+	values := WorkerPool.Values(results)
+	if len(values) != 4 || values[0][0] != "1" || values[3][0] != "4" {
+		t.Errorf("resumed run returned %v, want the values of every job in order", values)
+	}
+}
+
+func TestPaginateResumesFromThePageToken(t *testing.T) {
+	dir := t.TempDir()
+	pages := map[string][]string{"": {"a", "b"}, "2": {"c", "d"}, "4": {"e"}}
+	next := map[string]string{"": "2", "2": "4", "4": ""}
+
+	// The first run fails on the second page
+	first := Checkpoint.New(dir)
+	items, err := Checkpoint.Paginate(first, "audit", "items", func(pageToken string) ([]string, string, error) {
+		if pageToken == "2" {
+			return nil, "", errors.New("failed")
+		}
+		return pages[pageToken], next[pageToken], nil
+	})
+	if err == nil || len(items) != 2 {
+		t.Fatalf("first run returned %v, %v, want the first page and an error", items, err)
+	}
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// The resumed run starts from the second page
+	resumed, err := Checkpoint.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var tokens []string
+	items, err = Checkpoint.Paginate(resumed, "audit", "items", func(pageToken string) ([]string, string, error) {
+		tokens = append(tokens, pageToken)
+		return pages[pageToken], next[pageToken], nil
+	})
+	if err != nil {
+		t.Fatalf("Paginate: %v", err)
+	}
+	if len(tokens) != 2 || tokens[0] != "2" {
+		t.Errorf("resumed run requested pages %q, want [2 4]", tokens)
+	}
+	if len(items) != 5 {
+		t.Errorf("resumed run returned %v, want the 5 items", items)
+	}
+}
+
+func TestMarkDone(t *testing.T) {
+	dir := t.TempDir()
+	checkpoint := Checkpoint.New(dir)
+	if err := checkpoint.MarkDone("audit"); err != nil {
+		t.Fatalf("MarkDone: %v", err)
+	}
+	if !Checkpoint.Exists(dir) {
+		t.Fatal("MarkDone did not save the checkpoint")
+	}
+	resumed, err := Checkpoint.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !resumed.Done("audit") || resumed.Done("other") {
+		t.Error("Done does not match the audits marked as done")
+	}
+
+	// A nil checkpoint records nothing
+	var disabled *Checkpoint.Checkpoint
+	if disabled.Done("audit") || disabled.MarkDone("audit") != nil || disabled.Save() != nil {
+		t.Error("a nil checkpoint is not a no-op")
+	}
+}
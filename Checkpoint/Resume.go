@@ -0,0 +1,185 @@
+package Checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	"log"
+)
+
+// Paginate calls the fetch function for every page until the last one, resuming the listing recorded under the name by a previous run.
+// The items and the token of the next page are recorded after every page, the items listed so far are returned with the error.
+func Paginate[T any](checkpoint *Checkpoint, audit, name string, fetch func(pageToken string) ([]T, string, error)) ([]T, error) {
+	var items []T
+	pageToken := ""
+
+	// Reload the pages listed by the previous run
+	if checkpoint != nil {
+		checkpoint.mu.Lock()
+		list := checkpoint.list(audit, name)
+		for _, data := range list.Items {
+			var item T
+			if err := json.Unmarshal(data, &item); err != nil {
+				checkpoint.mu.Unlock()
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		done := list.Done
+		pageToken = list.PageToken
+		checkpoint.mu.Unlock()
+		if done {
+			log.Printf("Resumed %d %s listed by the previous run", len(items), name)
+			return items, nil
+		}
+		if pageToken != "" {
+			log.Printf("Resuming the listing of %s after %d items", name, len(items))
+		}
+	}
+
+	for {
+		page, nextPageToken, err := fetch(pageToken)
+		if err != nil {
+			return items, err
+		}
+		items = append(items, page...)
+		if err := checkpoint.recordPage(audit, name, page, nextPageToken); err != nil {
+			return items, err
+		}
+		if nextPageToken == "" {
+			return items, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// recordPage records the items of a page and the token of the next page
+func (receiver *Checkpoint) recordPage(audit, name string, items any, nextPageToken string) error {
+	if receiver == nil {
+		return nil
+	}
+	// Encode the page as a list of items
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	var encoded []json.RawMessage
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	list := receiver.list(audit, name)
+	list.Items = append(list.Items, encoded...)
+	list.PageToken = nextPageToken
+	list.Done = nextPageToken == ""
+	receiver.changed()
+	return nil
+}
+
+// Run executes the work over the jobs with WorkerPool.Run, recording the result of every job that succeeds under its key.
+// The jobs completed by a previous run are not executed again and get the result recorded under their key.
+func Run[J, R any](ctx context.Context, checkpoint *Checkpoint, audit, name string, jobs []J, key func(job J) string, options WorkerPool.Options, work func(ctx context.Context, job J) (R, error)) []WorkerPool.Result[R] {
+	results := make([]WorkerPool.Result[R], len(jobs))
+
+	// Restore the results recorded by the previous run
+	var pending []int
+	recorded := checkpoint.recorded(audit, name)
+	for i, job := range jobs {
+		if data, ok := recorded[key(job)]; ok {
+			var value R
+			if err := json.Unmarshal(data, &value); err == nil {
+				results[i] = WorkerPool.Result[R]{Index: i, Value: value}
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+	if skipped := len(jobs) - len(pending); skipped > 0 {
+		log.Printf("Skipping %d of %d %s completed by the previous run", skipped, len(jobs), name)
+	}
+
+	// Execute the remaining jobs and record their results
+	pendingResults := WorkerPool.Run(ctx, pending, options, func(ctx context.Context, i int) (R, error) {
+		value, err := work(ctx, jobs[i])
+		if err == nil {
+			checkpoint.recordJob(audit, name, key(jobs[i]), value)
+		}
+		return value, err
+	})
+	for _, result := range pendingResults {
+		i := pending[result.Index]
+		results[i] = WorkerPool.Result[R]{Index: i, Value: result.Value, Err: result.Err}
+	}
+	return results
+}
+
+// recorded returns a copy of the results recorded for a job set
+func (receiver *Checkpoint) recorded(audit, name string) map[string]json.RawMessage {
+	recorded := make(map[string]json.RawMessage)
+	if receiver == nil {
+		return recorded
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	for key, data := range receiver.jobs(audit, name) {
+		recorded[key] = data
+	}
+	return recorded
+}
+
+// recordJob records the result of a completed job
+func (receiver *Checkpoint) recordJob(audit, name, key string, value any) {
+	if receiver == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Unable to record %s %s in the checkpoint: %s", name, key, err.Error())
+		return
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.jobs(audit, name)[key] = data
+	receiver.changed()
+}
+
+// RecordErrors records the errors of the entities skipped by the audit and the number of entities it processed, MarkDone saves them.
+// A later run reloads them with RecordedErrors when it skips the audit so its error log and error rate still cover it.
+func (receiver *Checkpoint) RecordErrors(audit string, errors any, processed int) error {
+	if receiver == nil {
+		return nil
+	}
+	data, err := json.Marshal(errors)
+	if err != nil {
+		return err
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	progress := receiver.progress(audit)
+	progress.Errors = data
+	progress.Processed = processed
+	receiver.changed()
+	return nil
+}
+
+// RecordedErrors returns the errors and the number of processed entities recorded for the audit by a previous run
+func RecordedErrors[T any](checkpoint *Checkpoint, audit string) ([]T, int, error) {
+	if checkpoint == nil {
+		return nil, 0, nil
+	}
+	checkpoint.mu.Lock()
+	defer checkpoint.mu.Unlock()
+	progress, ok := checkpoint.state.Audits[audit]
+	if !ok {
+		return nil, 0, nil
+	}
+	var errors []T
+	if len(progress.Errors) > 0 {
+		if err := json.Unmarshal(progress.Errors, &errors); err != nil {
+			return nil, 0, err
+		}
+	}
+	return errors, progress.Processed, nil
+}
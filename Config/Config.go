@@ -0,0 +1,457 @@
+package Config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"google.golang.org/api/googleapi"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix This is the prefix of the environment variables overriding the config file
+const EnvPrefix = "GSA_AUDIT_"
+
+// Config This is the configuration of a run, read from the defaults, the config file, the environment and the flags in that order
+type Config struct {
+	Credentials Credentials `yaml:"credentials"`
+	// CustomerID is the customer of the Directory API requests, "my_customer" is the customer of the authenticated user
+	CustomerID string `yaml:"customer_id"`
+	// Domains are the domains of the organization, the members of other domains are external
+	Domains []string `yaml:"domains"`
+	// OrgUnit is the organizational unit whose subtree the user audits are restricted to, empty covers every user
+	OrgUnit string `yaml:"org_unit"`
+	// Audits are the audits run by the "run" command, empty runs every audit
+	Audits      []string    `yaml:"audits"`
+	Concurrency Concurrency `yaml:"concurrency"`
+	Retry       Retry       `yaml:"retry"`
+	Fields      Fields      `yaml:"fields"`
+	Inactivity  Inactivity  `yaml:"inactivity"`
+	Output      Output      `yaml:"output"`
+	Upload      Upload      `yaml:"upload"`
+	// Timeout stops the audits after the given duration, zero means no timeout
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxErrorRate is the highest rate of skipped entities of a successful run, between 0 and 1
+	MaxErrorRate float64 `yaml:"max_error_rate"`
+	// Tenants are the Workspace customers assessed one after the other by a multi-customer run, empty assesses the customer of the credentials
+	Tenants []Tenant `yaml:"tenants"`
+	// Resume is the reports directory of the interrupted run to continue, it is only set by the -resume flag
+	Resume string `yaml:"-"`
+}
+
+// Tenant This is a Workspace customer of a multi-customer run, its reports are written to the folder of its name in the output path.
+// Its empty credentials and customer ID keep those of the run, its domains are never inherited and default to its verified domains.
+type Tenant struct {
+	Name        string      `yaml:"name"`
+	Credentials Credentials `yaml:"credentials"`
+	CustomerID  string      `yaml:"customer_id"`
+	Domains     []string    `yaml:"domains"`
+}
+
+// Credentials This is the section of the tokens and of the delegation key
+type Credentials struct {
+	AccessToken  string `yaml:"access_token"`
+	RefreshToken string `yaml:"refresh_token"`
+	// DelegationKeyPath is the service account key used by the audits impersonating the users
+	DelegationKeyPath string `yaml:"delegation_key_path"`
+}
+
+// Concurrency This is the section of the number of entities processed at the same time
+type Concurrency struct {
+	// Audits is the number of entities an audit processes at the same time
+	Audits int `yaml:"audits"`
+	// Directory is the number of users processed at the same time by the DirectoryAPI, zero keeps its default
+	Directory int `yaml:"directory"`
+	// Drive is the number of drives processed at the same time by the DriveAPI, zero keeps its default
+	Drive int `yaml:"drive"`
+}
+
+// Retry This is the section of the retry policy of the Google API wrappers, the values not set keep the defaults of every wrapper.
+// The values are pointers so a zero, like a max_retries of 0 disabling the retries, is told from a value not set.
+type Retry struct {
+	MaxRetries     *int           `yaml:"max_retries"`
+	InitialBackoff *time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     *time.Duration `yaml:"max_backoff"`
+	Multiplier     *float64       `yaml:"multiplier"`
+	Jitter         *float64       `yaml:"jitter"`
+}
+
+// Fields This is the section of the field masks of the Google API calls, empty sends the minimal mask of every call
+type Fields struct {
+	// All requests every field of every response with the "*" mask
+	All bool `yaml:"all"`
+	// Masks overrides the mask of the calls by call name, like tokens.list
+	Masks map[string]string `yaml:"masks"`
+}
+
+// Inactivity This is the section of the thresholds of the inactive users audit, zero values keep the defaults of the audit
+type Inactivity struct {
+	// ThresholdDays are the numbers of days without a login after which a user is inactive, the smallest makes a user dormant
+	ThresholdDays []int `yaml:"threshold_days"`
+	// NewAccountDays is the age in days under which an account that never logged in is new rather than dormant
+	NewAccountDays int `yaml:"new_account_days"`
+}
+
+// Output This is the section of the local reports
+type Output struct {
+	// Path is the directory the reports are written to
+	Path string `yaml:"path"`
+	// Formats are the formats every report is written in
+	Formats []string `yaml:"formats"`
+}
+
+// Upload This is the section of the upload of the zipped reports to Google Drive
+type Upload struct {
+	Enabled bool `yaml:"enabled"`
+	// DriveFolder is the id of the Google Drive folder the reports are uploaded to
+	DriveFolder string `yaml:"drive_folder"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	return &Config{
+		CustomerID:   "my_customer",
+		Concurrency:  Concurrency{Audits: 100},
+		Output:       Output{Path: "output_" + time.Now().Format(time.RFC3339), Formats: []string{"csv"}},
+		Upload:       Upload{Enabled: true, DriveFolder: "root"},
+		MaxErrorRate: 0.05,
+		Credentials:  Credentials{DelegationKeyPath: "svcKey.json"},
+	}
+}
+
+// LoadFile overrides the configuration with the YAML or JSON file, the unknown keys are rejected
+func (receiver *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(receiver); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// LoadEnv overrides the configuration with the GSA_AUDIT_* environment variables returned by lookup
+func (receiver *Config) LoadEnv(lookup func(key string) (string, bool)) error {
+	for _, variable := range receiver.variables() {
+		value, ok := lookup(EnvPrefix + variable.name)
+		if !ok {
+			continue
+		}
+		if err := variable.set(value); err != nil {
+			return fmt.Errorf("invalid %s%s: %v", EnvPrefix, variable.name, err)
+		}
+	}
+	return nil
+}
+
+// variable This is an environment variable and the setter of the field it overrides
+type variable struct {
+	name string
+	set  func(value string) error
+}
+
+// variables returns the environment variables of the configuration
+func (receiver *Config) variables() []variable {
+	setString := func(field *string) func(string) error {
+		return func(value string) error {
+			*field = value
+			return nil
+		}
+	}
+	setList := func(field *[]string) func(string) error {
+		return func(value string) error {
+			*field = splitList(value)
+			return nil
+		}
+	}
+	return []variable{
+		{"ACCESS_TOKEN", setString(&receiver.Credentials.AccessToken)},
+		{"REFRESH_TOKEN", setString(&receiver.Credentials.RefreshToken)},
+		{"DELEGATION_KEY_PATH", setString(&receiver.Credentials.DelegationKeyPath)},
+		{"CUSTOMER_ID", setString(&receiver.CustomerID)},
+		{"DOMAINS", setList(&receiver.Domains)},
+		{"ORG_UNIT", setString(&receiver.OrgUnit)},
+		{"AUDITS", setList(&receiver.Audits)},
+		{"INACTIVE_DAYS", func(value string) (err error) {
+			receiver.Inactivity.ThresholdDays, err = parseIntList(value)
+			return err
+		}},
+		{"NEW_ACCOUNT_DAYS", func(value string) (err error) {
+			receiver.Inactivity.NewAccountDays, err = strconv.Atoi(value)
+			return err
+		}},
+		{"OUTPUT_PATH", setString(&receiver.Output.Path)},
+		{"OUTPUT_FORMATS", setList(&receiver.Output.Formats)},
+		{"DRIVE_FOLDER", setString(&receiver.Upload.DriveFolder)},
+		{"UPLOAD_ENABLED", func(value string) (err error) {
+			receiver.Upload.Enabled, err = strconv.ParseBool(value)
+			return err
+		}},
+		{"CONCURRENCY", func(value string) (err error) {
+			receiver.Concurrency.Audits, err = strconv.Atoi(value)
+			return err
+		}},
+		{"MAX_RETRIES", func(value string) error {
+			maxRetries, err := strconv.Atoi(value)
+			if err == nil {
+				receiver.Retry.MaxRetries = &maxRetries
+			}
+			return err
+		}},
+		{"ALL_FIELDS", func(value string) (err error) {
+			receiver.Fields.All, err = strconv.ParseBool(value)
+			return err
+		}},
+		{"TIMEOUT", func(value string) (err error) {
+			receiver.Timeout, err = time.ParseDuration(value)
+			return err
+		}},
+		{"MAX_ERROR_RATE", func(value string) (err error) {
+			receiver.MaxErrorRate, err = strconv.ParseFloat(value, 64)
+			return err
+		}},
+	}
+}
+
+// Validate returns every problem of the configuration for the given audits in a single error
+func (receiver *Config) Validate(auditors []Audit.Auditor) error {
+	var problems []string
+	if len(receiver.Tenants) == 0 {
+		problems = append(problems, receiver.validateCustomer(auditors)...)
+	}
+	names := make(map[string]bool)
+	for i, tenant := range receiver.Tenants {
+		if tenant.Name == "" || tenant.Name == "." || tenant.Name == ".." || strings.ContainsAny(tenant.Name, `/\`) {
+			problems = append(problems, fmt.Sprintf("invalid name %q of tenant %d, expected a folder name like agency-a", tenant.Name, i+1))
+			continue
+		}
+		if names[strings.ToLower(tenant.Name)] {
+			problems = append(problems, fmt.Sprintf("duplicate tenant %q", tenant.Name))
+		}
+		names[strings.ToLower(tenant.Name)] = true
+		for _, problem := range receiver.ForTenant(tenant).validateCustomer(auditors) {
+			problems = append(problems, fmt.Sprintf("tenant %s: %s", tenant.Name, problem))
+		}
+	}
+	if receiver.OrgUnit != "" && !strings.HasPrefix(receiver.OrgUnit, "/") {
+		problems = append(problems, fmt.Sprintf("invalid org_unit %q, expected a path like /Engineering", receiver.OrgUnit))
+	}
+	for _, name := range receiver.Audits {
+		if Audit.Get(name) == nil {
+			problems = append(problems, fmt.Sprintf("unknown audit %q", name))
+		}
+	}
+	if receiver.Concurrency.Audits < 1 {
+		problems = append(problems, "concurrency.audits must be at least 1")
+	}
+	if receiver.Concurrency.Directory < 0 || receiver.Concurrency.Drive < 0 {
+		problems = append(problems, "concurrency.directory and concurrency.drive must not be negative")
+	}
+	retry := receiver.Retry
+	if (retry.MaxRetries != nil && *retry.MaxRetries < 0) || (retry.InitialBackoff != nil && *retry.InitialBackoff < 0) ||
+		(retry.MaxBackoff != nil && *retry.MaxBackoff < 0) || (retry.Multiplier != nil && *retry.Multiplier < 0) {
+		problems = append(problems, "retry values must not be negative")
+	}
+	if retry.Jitter != nil && (*retry.Jitter < 0 || *retry.Jitter > 1) {
+		problems = append(problems, "retry.jitter must be between 0 and 1")
+	}
+	for call := range receiver.Fields.Masks {
+		if _, ok := GoogleAPI.DefaultFieldMasks[call]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown call %q in fields.masks, expected one of %s", call, strings.Join(GoogleAPI.FieldCalls(), ", ")))
+		}
+	}
+	for _, days := range receiver.Inactivity.ThresholdDays {
+		if days < 1 {
+			problems = append(problems, "inactivity.threshold_days must be at least 1")
+			break
+		}
+	}
+	if receiver.Inactivity.NewAccountDays < 0 {
+		problems = append(problems, "inactivity.new_account_days must not be negative")
+	}
+	if receiver.Output.Path == "" {
+		problems = append(problems, "output.path must not be empty")
+	}
+	if len(receiver.Output.Formats) == 0 {
+		problems = append(problems, "output.formats must not be empty")
+	}
+	for _, format := range receiver.Output.Formats {
+		if !Report.IsFormat(format) {
+			problems = append(problems, fmt.Sprintf("unknown output format %q, expected one of %s", format, strings.Join(Report.Formats(), ", ")))
+		}
+	}
+	if receiver.Upload.Enabled && receiver.Upload.DriveFolder == "" {
+		problems = append(problems, "upload.drive_folder must not be empty when the upload is enabled")
+	}
+	if receiver.Timeout < 0 {
+		problems = append(problems, "timeout must not be negative")
+	}
+	if receiver.MaxErrorRate < 0 || receiver.MaxErrorRate > 1 {
+		problems = append(problems, "max_error_rate must be between 0 and 1")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateCustomer returns the problems of the credentials, customer ID and domains of the customer assessed by the configuration
+func (receiver *Config) validateCustomer(auditors []Audit.Auditor) []string {
+	var problems []string
+	if receiver.Credentials.AccessToken == "" && receiver.Credentials.RefreshToken == "" {
+		problems = append(problems, "missing access_token or refresh_token, generate them with the auth command")
+	}
+	if Audit.NeedsDelegationKey(auditors...) {
+		if _, err := os.Stat(receiver.Credentials.DelegationKeyPath); err != nil {
+			problems = append(problems, fmt.Sprintf("unable to read delegation key %s: %v", receiver.Credentials.DelegationKeyPath, err))
+		}
+	}
+	if receiver.CustomerID == "" {
+		problems = append(problems, "customer_id must not be empty")
+	}
+	for _, domain := range receiver.Domains {
+		if strings.ContainsAny(domain, "@ ") {
+			problems = append(problems, fmt.Sprintf("invalid domain %q, expected a domain like example.com", domain))
+		}
+	}
+	return problems
+}
+
+// ForTenant returns the configuration of the run of a tenant, writing to its folder of the output path and of the run to resume
+func (receiver *Config) ForTenant(tenant Tenant) *Config {
+	config := *receiver
+	config.Tenants = nil
+	if tenant.Credentials.AccessToken != "" || tenant.Credentials.RefreshToken != "" {
+		config.Credentials.AccessToken = tenant.Credentials.AccessToken
+		config.Credentials.RefreshToken = tenant.Credentials.RefreshToken
+	}
+	if tenant.Credentials.DelegationKeyPath != "" {
+		config.Credentials.DelegationKeyPath = tenant.Credentials.DelegationKeyPath
+	}
+	if tenant.CustomerID != "" {
+		config.CustomerID = tenant.CustomerID
+	}
+	config.Domains = tenant.Domains
+	config.Output.Path = filepath.Join(receiver.Output.Path, tenant.Name)
+	if receiver.Resume != "" {
+		config.Resume = filepath.Join(receiver.Resume, tenant.Name)
+	}
+	return &config
+}
+
+// Auditors returns the audits selected by the configuration, every audit when none is selected
+func (receiver *Config) Auditors() []Audit.Auditor {
+	if len(receiver.Audits) == 0 {
+		return Audit.All()
+	}
+	var auditors []Audit.Auditor
+	for _, name := range receiver.Audits {
+		if auditor := Audit.Get(name); auditor != nil {
+			auditors = append(auditors, auditor)
+		}
+	}
+	return auditors
+}
+
+// RetryOverride returns the values overriding the retry policies of the wrappers, nil when the retry section is empty
+func (receiver *Config) RetryOverride() *GoogleAPI.RetryOverride {
+	if receiver.Retry == (Retry{}) {
+		return nil
+	}
+	return &GoogleAPI.RetryOverride{
+		MaxRetries:     receiver.Retry.MaxRetries,
+		InitialBackoff: receiver.Retry.InitialBackoff,
+		MaxBackoff:     receiver.Retry.MaxBackoff,
+		Multiplier:     receiver.Retry.Multiplier,
+		Jitter:         receiver.Retry.Jitter,
+	}
+}
+
+// FieldMasks returns the field masks of the calls of the wrappers
+func (receiver *Config) FieldMasks() GoogleAPI.FieldMasks {
+	masks := GoogleAPI.FieldMasks{All: receiver.Fields.All}
+	for call, mask := range receiver.Fields.Masks {
+		if masks.Calls == nil {
+			masks.Calls = make(map[string]googleapi.Field)
+		}
+		masks.Calls[call] = googleapi.Field(mask)
+	}
+	return masks
+}
+
+// splitList splits a comma separated list and drops the empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseIntList parses a comma separated list of integers
+func parseIntList(value string) ([]int, error) {
+	var list []int
+	for _, item := range splitList(value) {
+		number, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, number)
+	}
+	return list, nil
+}
+
+// IntListFlag This is a flag.Value setting a list of integers from a comma separated value
+type IntListFlag struct {
+	List *[]int
+}
+
+// String returns the comma separated list
+func (receiver IntListFlag) String() string {
+	if receiver.List == nil {
+		return ""
+	}
+	items := make([]string, len(*receiver.List))
+	for i, number := range *receiver.List {
+		items[i] = strconv.Itoa(number)
+	}
+	return strings.Join(items, ",")
+}
+
+// Set replaces the list with the comma separated value
+func (receiver IntListFlag) Set(value string) (err error) {
+	*receiver.List, err = parseIntList(value)
+	return err
+}
+
+// ListFlag This is a flag.Value setting a list from a comma separated value
+type ListFlag struct {
+	List *[]string
+}
+
+// String returns the comma separated list
+func (receiver ListFlag) String() string {
+	if receiver.List == nil {
+		return ""
+	}
+	return strings.Join(*receiver.List, ",")
+}
+
+// Set replaces the list with the comma separated value
+func (receiver ListFlag) Set(value string) error {
+	*receiver.List = splitList(value)
+	return nil
+}
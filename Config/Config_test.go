@@ -0,0 +1,248 @@
+package Config_test
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Config"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestLoadFileYAML(t *testing.T) {
+	config := Config.Default()
+	path := writeFile(t, "config.yaml", `
+credentials:
+  refresh_token: refresh
+customer_id: C0123
+concurrency:
+  audits: 20
+retry:
+  max_retries: 3
+  initial_backoff: 500ms
+output:
+  formats: [csv]
+upload:
+  enabled: false
+timeout: 2h
+`)
+	if err := config.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if config.Credentials.RefreshToken != "refresh" || config.CustomerID != "C0123" || config.Concurrency.Audits != 20 {
+		t.Errorf("LoadFile = %+v", config)
+	}
+	if config.Retry.MaxRetries == nil || *config.Retry.MaxRetries != 3 || config.Retry.InitialBackoff == nil || *config.Retry.InitialBackoff != 500*time.Millisecond || config.Timeout != 2*time.Hour {
+		t.Errorf("LoadFile retry = %+v, timeout = %s", config.Retry, config.Timeout)
+	}
+	if config.Upload.Enabled || config.Upload.DriveFolder != "root" {
+		t.Errorf("LoadFile upload = %+v, want the upload disabled and the default folder", config.Upload)
+	}
+	if config.Credentials.DelegationKeyPath != "svcKey.json" {
+		t.Errorf("LoadFile dropped the default delegation key path")
+	}
+}
+
+func TestLoadFileJSON(t *testing.T) {
+	config := Config.Default()
+	path := writeFile(t, "config.json", `{"customer_id": "C0456", "audits": ["groups"]}`)
+	if err := config.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if config.CustomerID != "C0456" || len(config.Audits) != 1 || config.Audits[0] != "groups" {
+		t.Errorf("LoadFile = %+v", config)
+	}
+}
+
+func TestLoadFileRejectsUnknownKeys(t *testing.T) {
+	path := writeFile(t, "config.yaml", "customer: C0123\n")
+	if err := Config.Default().LoadFile(path); err == nil || !strings.Contains(err.Error(), "customer") {
+		t.Errorf("LoadFile error = %v, want the unknown key", err)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	config := Config.Default()
+	env := map[string]string{
+		"GSA_AUDIT_ACCESS_TOKEN":   "access",
+		"GSA_AUDIT_AUDITS":         "groups, inventory",
+		"GSA_AUDIT_UPLOAD_ENABLED": "false",
+		"GSA_AUDIT_TIMEOUT":        "30m",
+		"GSA_AUDIT_DOMAINS":        "example.com,example.org",
+		"GSA_AUDIT_INACTIVE_DAYS":  "60, 120",
+		"GSA_AUDIT_ORG_UNIT":       "/Engineering",
+		"GSA_AUDIT_ALL_FIELDS":     "true",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	if err := config.LoadEnv(lookup); err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	if config.Credentials.AccessToken != "access" || len(config.Audits) != 2 || config.Upload.Enabled || config.Timeout != 30*time.Minute || len(config.Domains) != 2 || len(config.Inactivity.ThresholdDays) != 2 || config.OrgUnit != "/Engineering" || !config.Fields.All {
+		t.Errorf("LoadEnv = %+v", config)
+	}
+
+	env["GSA_AUDIT_CONCURRENCY"] = "many"
+	if err := config.LoadEnv(lookup); err == nil || !strings.Contains(err.Error(), "GSA_AUDIT_CONCURRENCY") {
+		t.Errorf("LoadEnv error = %v, want the invalid variable", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	config := Config.Default()
+	config.Credentials.RefreshToken = "refresh"
+	if err := config.Validate(nil); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	config.Concurrency.Audits = 0
+	config.Output.Formats = []string{"xml"}
+	jitter := 2.0
+	config.Retry.Jitter = &jitter
+	config.Domains = []string{"admin@example.com"}
+	config.Inactivity.ThresholdDays = []int{90, 0}
+	config.OrgUnit = "Engineering"
+	config.Fields.Masks = map[string]string{"users.get": "id"}
+	err := config.Validate([]Audit.Auditor{})
+	if err == nil {
+		t.Fatal("Validate succeeded, want the invalid values")
+	}
+	for _, problem := range []string{"concurrency.audits", `"xml"`, "retry.jitter", "admin@example.com", "inactivity.threshold_days", "org_unit", `"users.get"`} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("Validate error = %v, want %s", err, problem)
+		}
+	}
+}
+
+func TestRetryOverride(t *testing.T) {
+	config := Config.Default()
+	if config.RetryOverride() != nil {
+		t.Error("RetryOverride of an empty retry section is not nil")
+	}
+
+	// The zeros set in the file override the policies, the values not set keep them
+	path := writeFile(t, "config.yaml", `
+retry:
+  max_retries: 0
+  jitter: 0
+`)
+	if err := config.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	policy := GoogleAPI.NewRetryPolicy(time.Second)
+	policy.Override(config.RetryOverride())
+	want := GoogleAPI.NewRetryPolicy(time.Second)
+	want.MaxRetries, want.Jitter = 0, 0
+	if *policy != *want {
+		t.Errorf("overridden policy = %+v, want %+v", policy, want)
+	}
+
+	config = Config.Default()
+	if err := config.LoadEnv(func(key string) (string, bool) { return "0", key == Config.EnvPrefix+"MAX_RETRIES" }); err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	if config.Retry.MaxRetries == nil || *config.Retry.MaxRetries != 0 {
+		t.Errorf("LoadEnv max retries = %v, want 0", config.Retry.MaxRetries)
+	}
+}
+
+func TestForTenant(t *testing.T) {
+	config := Config.Default()
+	path := writeFile(t, "config.yaml", `
+credentials:
+  refresh_token: refresh
+  delegation_key_path: key.json
+customer_id: C0123
+domains: [example.com]
+output:
+  path: reports
+tenants:
+  - name: agency
+  - name: bureau
+    credentials:
+      refresh_token: bureau-refresh
+    customer_id: C0456
+    domains: [bureau.example.gov]
+`)
+	if err := config.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if err := config.Validate(nil); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	// A tenant without credentials, customer ID or domains keeps the credentials and the customer ID of the run
+	agency := config.ForTenant(config.Tenants[0])
+	if agency.Credentials.RefreshToken != "refresh" || agency.CustomerID != "C0123" || len(agency.Domains) != 0 || len(agency.Tenants) != 0 {
+		t.Errorf("ForTenant(agency) = %+v", agency)
+	}
+	if agency.Output.Path != filepath.Join("reports", "agency") || agency.Resume != "" {
+		t.Errorf("ForTenant(agency) output = %s, resume = %s", agency.Output.Path, agency.Resume)
+	}
+	config.Resume = "reports"
+	bureau := config.ForTenant(config.Tenants[1])
+	if bureau.Credentials.RefreshToken != "bureau-refresh" || bureau.Credentials.DelegationKeyPath != "key.json" || bureau.CustomerID != "C0456" || bureau.Domains[0] != "bureau.example.gov" {
+		t.Errorf("ForTenant(bureau) = %+v", bureau)
+	}
+	if bureau.Resume != filepath.Join("reports", "bureau") {
+		t.Errorf("ForTenant(bureau) resume = %s", bureau.Resume)
+	}
+}
+
+func TestValidateTenants(t *testing.T) {
+	config := Config.Default()
+	config.Tenants = []Config.Tenant{
+		{Name: "../agency", Credentials: Config.Credentials{RefreshToken: "refresh"}},
+		{Name: "bureau", Credentials: Config.Credentials{RefreshToken: "refresh"}},
+		{Name: "Bureau", Credentials: Config.Credentials{RefreshToken: "refresh"}},
+		{Name: "office", Domains: []string{"admin@office.example.gov"}},
+	}
+	err := config.Validate(nil)
+	if err == nil {
+		t.Fatal("Validate succeeded, want the invalid tenants")
+	}
+	for _, problem := range []string{`"../agency"`, `"Bureau"`, "tenant office: ", "admin@office.example.gov"} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("Validate error = %v, want %s", err, problem)
+		}
+	}
+}
+
+func TestFieldMasks(t *testing.T) {
+	config := Config.Default()
+	path := writeFile(t, "config.yaml", `
+fields:
+  masks:
+    tokens.list: "*"
+`)
+	if err := config.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	masks := config.FieldMasks()
+	if masks.Get(GoogleAPI.TokensListCall) != GoogleAPI.AllFields {
+		t.Errorf("tokens.list mask = %s, want *", masks.Get(GoogleAPI.TokensListCall))
+	}
+	if masks.Get(GoogleAPI.UsersListCall) != GoogleAPI.DefaultFieldMasks[GoogleAPI.UsersListCall] {
+		t.Errorf("users.list mask = %s, want the default mask", masks.Get(GoogleAPI.UsersListCall))
+	}
+}
+
+func TestExampleConfig(t *testing.T) {
+	config := Config.Default()
+	if err := config.LoadFile(filepath.Join("..", "config.example.yaml")); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+}
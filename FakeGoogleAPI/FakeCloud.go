@@ -0,0 +1,55 @@
+package FakeGoogleAPI
+
+import (
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/iam/v1"
+	"net/http"
+)
+
+// serveCloud answers the Cloud Resource Manager and IAM API requests, the segments follow "v1"
+func (receiver *Server) serveCloud(w http.ResponseWriter, r *http.Request, segments []string) bool {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	tenant := receiver.tenant
+
+	switch {
+	case len(segments) == 1 && segments[0] == "projects":
+		start, end, next := receiver.page(r, "pageSize", len(tenant.Projects))
+		writeJSON(w, &cloudresourcemanager.ListProjectsResponse{Projects: tenant.Projects[start:end], NextPageToken: next})
+
+	case len(segments) == 3 && segments[0] == "projects" && segments[2] == "serviceAccounts":
+		accounts, ok := tenant.ServiceAccounts[segments[1]]
+		if !ok && !tenant.hasProject(segments[1]) {
+			writeError(w, http.StatusNotFound, "notFound", "Project not found: "+segments[1])
+			break
+		}
+		start, end, next := receiver.page(r, "pageSize", len(accounts))
+		writeJSON(w, &iam.ListServiceAccountsResponse{Accounts: accounts[start:end], NextPageToken: next})
+
+	case len(segments) == 4 && segments[0] == "projects" && segments[1] == "-" && segments[2] == "serviceAccounts":
+		// The wildcard project looks the account up by email or unique id in every project
+		for _, accounts := range tenant.ServiceAccounts {
+			for _, account := range accounts {
+				if account.Email == segments[3] || account.UniqueId == segments[3] {
+					writeJSON(w, account)
+					return true
+				}
+			}
+		}
+		writeError(w, http.StatusNotFound, "notFound", "Service account not found: "+segments[3])
+
+	default:
+		return false
+	}
+	return true
+}
+
+// hasProject returns true if the tenant has a project with the given id
+func (receiver *Tenant) hasProject(projectId string) bool {
+	for _, project := range receiver.Projects {
+		if project.ProjectId == projectId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package FakeGoogleAPI
+
+import (
+	directory "google.golang.org/api/admin/directory/v1"
+	"net/http"
+	"strings"
+)
+
+// serveDirectory answers the Admin SDK Directory API requests, the segments follow "admin/directory/v1"
+func (receiver *Server) serveDirectory(w http.ResponseWriter, r *http.Request, segments []string) bool {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	tenant := receiver.tenant
+
+	switch {
+	case len(segments) == 1 && segments[0] == "users":
+		start, end, next := receiver.page(r, "maxResults", len(tenant.Users))
+		writeJSON(w, &directory.Users{Users: tenant.Users[start:end], NextPageToken: next})
+
+	case len(segments) == 3 && segments[0] == "users" && segments[2] == "tokens":
+		user := tenant.findUser(segments[1])
+		if user == nil {
+			writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: userKey")
+			break
+		}
+		writeJSON(w, &directory.Tokens{Items: tenant.Tokens[user.PrimaryEmail]})
+
+	case len(segments) == 3 && segments[0] == "users" && segments[2] == "asps":
+		user := tenant.findUser(segments[1])
+		if user == nil {
+			writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: userKey")
+			break
+		}
+		writeJSON(w, &directory.Asps{Items: tenant.ASPs[user.PrimaryEmail]})
+
+	case len(segments) == 3 && segments[0] == "users" && segments[2] == "verificationCodes":
+		user := tenant.findUser(segments[1])
+		if user == nil {
+			writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: userKey")
+			break
+		}
+		writeJSON(w, &directory.VerificationCodes{Items: tenant.VerificationCodes[user.PrimaryEmail]})
+
+	case len(segments) == 1 && segments[0] == "groups":
+		groups := tenant.Groups
+		// The userKey parameter lists the groups the member belongs to
+		if userKey := r.URL.Query().Get("userKey"); userKey != "" {
+			groups = nil
+			for _, group := range tenant.Groups {
+				for _, member := range tenant.Members[group.Email] {
+					if member.Email == userKey || member.Id == userKey {
+						groups = append(groups, group)
+						break
+					}
+				}
+			}
+		}
+		start, end, next := receiver.page(r, "maxResults", len(groups))
+		writeJSON(w, &directory.Groups{Groups: groups[start:end], NextPageToken: next})
+
+	case len(segments) == 3 && segments[0] == "groups" && segments[2] == "members":
+		group := tenant.findGroup(segments[1])
+		if group == nil {
+			writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupKey")
+			break
+		}
+		members := tenant.Members[group.Email]
+		// The roles parameter is a comma separated list of the roles to return
+		if roles := r.URL.Query().Get("roles"); roles != "" {
+			members = nil
+			for _, member := range tenant.Members[group.Email] {
+				if containsFold(strings.Split(roles, ","), member.Role) {
+					members = append(members, member)
+				}
+			}
+		}
+		start, end, next := receiver.page(r, "maxResults", len(members))
+		writeJSON(w, &directory.Members{Members: members[start:end], NextPageToken: next})
+
+	case len(segments) == 3 && segments[0] == "customer" && segments[2] == "roles":
+		start, end, next := receiver.page(r, "maxResults", len(tenant.Roles))
+		writeJSON(w, &directory.Roles{Items: tenant.Roles[start:end], NextPageToken: next})
+
+	case len(segments) == 5 && segments[0] == "customer" && segments[2] == "roles" && segments[3] == "ALL" && segments[4] == "privileges":
+		writeJSON(w, &directory.Privileges{Items: tenant.Privileges})
+
+	case len(segments) == 3 && segments[0] == "customer" && segments[2] == "roleassignments":
+		assignments := tenant.RoleAssignments
+		// The userKey parameter lists the assignments of a user or group
+		if userKey := r.URL.Query().Get("userKey"); userKey != "" {
+			if user := tenant.findUser(userKey); user != nil {
+				userKey = user.Id
+			}
+			assignments = nil
+			for _, assignment := range tenant.RoleAssignments {
+				if assignment.AssignedTo == userKey {
+					assignments = append(assignments, assignment)
+				}
+			}
+		}
+		start, end, next := receiver.page(r, "maxResults", len(assignments))
+		writeJSON(w, &directory.RoleAssignments{Items: assignments[start:end], NextPageToken: next})
+
+	case len(segments) == 4 && segments[0] == "customer" && segments[2] == "devices" && segments[3] == "mobile":
+		start, end, next := receiver.page(r, "maxResults", len(tenant.MobileDevices))
+		writeJSON(w, &directory.MobileDevices{Mobiledevices: tenant.MobileDevices[start:end], NextPageToken: next})
+
+	case len(segments) == 4 && segments[0] == "customer" && segments[2] == "devices" && segments[3] == "chromeos":
+		start, end, next := receiver.page(r, "maxResults", len(tenant.ChromeOSDevices))
+		writeJSON(w, &directory.ChromeOsDevices{Chromeosdevices: tenant.ChromeOSDevices[start:end], NextPageToken: next})
+
+	case len(segments) == 3 && segments[0] == "customer" && segments[2] == "domains":
+		writeJSON(w, &directory.Domains2{Domains: tenant.Domains})
+
+	case len(segments) == 3 && segments[0] == "customer" && segments[2] == "domainaliases":
+		writeJSON(w, &directory.DomainAliases{DomainAliases: tenant.DomainAliases})
+
+	case len(segments) == 4 && segments[0] == "customer" && segments[2] == "resources" && segments[3] == "buildings":
+		start, end, next := receiver.page(r, "maxResults", len(tenant.Buildings))
+		writeJSON(w, &directory.Buildings{Buildings: tenant.Buildings[start:end], NextPageToken: next})
+
+	case len(segments) == 4 && segments[0] == "customer" && segments[2] == "resources" && segments[3] == "calendars":
+		start, end, next := receiver.page(r, "maxResults", len(tenant.CalendarResources))
+		writeJSON(w, &directory.CalendarResources{Items: tenant.CalendarResources[start:end], NextPageToken: next})
+
+	case len(segments) == 3 && segments[0] == "customer" && segments[2] == "orgunits":
+		// The units are not paginated by the Directory API, every unit is returned for the type "all"
+		writeJSON(w, &directory.OrgUnits{OrganizationUnits: tenant.OrgUnits})
+
+	default:
+		return false
+	}
+	return true
+}
+
+// containsFold returns true if the value is in the list ignoring the case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
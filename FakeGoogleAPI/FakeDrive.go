@@ -0,0 +1,225 @@
+package FakeGoogleAPI
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/api/drive/v3"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// subjectTokenPrefix This is the prefix of the access tokens issued to impersonated users
+const subjectTokenPrefix = "fake-subject:"
+
+// mimeTypeQuery This is the regular expression matching the mimeType clause of a files.list query
+var mimeTypeQuery = regexp.MustCompile(`mimeType\s*=\s*'([^']*)'`)
+
+// upload This is a resumable upload in progress
+type upload struct {
+	metaData *drive.File
+	data     []byte
+}
+
+// serveDrive answers the Drive API requests, the segments follow "drive/v3"
+func (receiver *Server) serveDrive(w http.ResponseWriter, r *http.Request, segments []string) bool {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	tenant := receiver.tenant
+
+	switch {
+	case len(segments) == 1 && segments[0] == "drives":
+		start, end, next := receiver.page(r, "pageSize", len(tenant.Drives))
+		writeJSON(w, &drive.DriveList{Drives: tenant.Drives[start:end], NextPageToken: next})
+
+	case len(segments) == 1 && segments[0] == "files" && r.Method == http.MethodGet:
+		files := filterFiles(tenant.Files, r.URL.Query().Get("q"), requestSubject(r))
+		start, end, next := receiver.page(r, "pageSize", len(files))
+		writeJSON(w, &drive.FileList{Files: files[start:end], NextPageToken: next})
+
+	case len(segments) == 3 && segments[0] == "files" && segments[2] == "permissions":
+		permissions, ok := tenant.Permissions[segments[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "notFound", "File not found: "+segments[1])
+			break
+		}
+		start, end, next := receiver.page(r, "pageSize", len(permissions))
+		writeJSON(w, &drive.PermissionList{Permissions: permissions[start:end], NextPageToken: next})
+
+	default:
+		return false
+	}
+	return true
+}
+
+// serveUpload answers the multipart and resumable uploads of the files.create call, the segments follow "upload/drive/v3"
+func (receiver *Server) serveUpload(w http.ResponseWriter, r *http.Request, segments []string) bool {
+	if len(segments) != 1 || segments[0] != "files" {
+		return false
+	}
+
+	switch r.URL.Query().Get("uploadType") {
+	case "multipart":
+		metaData, data, err := readMultipart(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+			return true
+		}
+		writeJSON(w, receiver.createFile(metaData, data))
+
+	case "resumable":
+		receiver.serveResumable(w, r)
+
+	default:
+		writeError(w, http.StatusBadRequest, "badRequest", "unsupported uploadType")
+	}
+	return true
+}
+
+// serveResumable starts a resumable upload or receives one of its chunks
+func (receiver *Server) serveResumable(w http.ResponseWriter, r *http.Request) {
+	receiver.mu.Lock()
+	uploadId := r.URL.Query().Get("upload_id")
+
+	// Start the upload and send the session URL
+	if uploadId == "" {
+		metaData := &drive.File{}
+		if err := json.NewDecoder(r.Body).Decode(metaData); err != nil && err != io.EOF {
+			receiver.mu.Unlock()
+			writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+			return
+		}
+		uploadId = strconv.Itoa(len(receiver.uploads) + 1)
+		receiver.uploads[uploadId] = &upload{metaData: metaData}
+		receiver.mu.Unlock()
+		w.Header().Set("Location", receiver.URL+"/upload/drive/v3/files?uploadType=resumable&upload_id="+uploadId)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Append the chunk to the upload
+	current, ok := receiver.uploads[uploadId]
+	receiver.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "notFound", "upload not found: "+uploadId)
+		return
+	}
+	chunk, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+		return
+	}
+	current.data = append(current.data, chunk...)
+
+	// The total is known once the last chunk is sent, "bytes 0-99/100" or "bytes */100"
+	contentRange := r.Header.Get("Content-Range")
+	total, err := strconv.Atoi(contentRange[strings.LastIndex(contentRange, "/")+1:])
+	if err != nil || len(current.data) < total {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(current.data)-1))
+		w.WriteHeader(http.StatusPermanentRedirect)
+		return
+	}
+	writeJSON(w, receiver.createFile(current.metaData, current.data))
+}
+
+// createFile stores an uploaded file and returns its metadata
+func (receiver *Server) createFile(metaData *drive.File, data []byte) *drive.File {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	metaData.Id = fmt.Sprintf("upload-%d", len(receiver.tenant.Uploads)+1)
+	metaData.Size = int64(len(data))
+	metaData.WebViewLink = "https://drive.google.com/file/d/" + metaData.Id + "/view"
+	receiver.tenant.Uploads = append(receiver.tenant.Uploads, &UploadedFile{MetaData: metaData, Data: data})
+	return metaData
+}
+
+// readMultipart reads the metadata and the media of a multipart upload
+func readMultipart(r *http.Request) (*drive.File, []byte, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := multipart.NewReader(r.Body, params["boundary"])
+
+	// The first part is the metadata
+	part, err := reader.NextPart()
+	if err != nil {
+		return nil, nil, err
+	}
+	metaData := &drive.File{}
+	if err := json.NewDecoder(part).Decode(metaData); err != nil {
+		return nil, nil, err
+	}
+
+	// The second part is the media
+	part, err = reader.NextPart()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := io.ReadAll(part)
+	return metaData, data, err
+}
+
+// filterFiles returns the files matching the mimeType and "'me' in owners" clauses of the query
+func filterFiles(files []*drive.File, q, subject string) []*drive.File {
+	mimeType := ""
+	if match := mimeTypeQuery.FindStringSubmatch(q); match != nil {
+		mimeType = match[1]
+	}
+	ownedByMe := strings.Contains(q, "'me' in owners")
+
+	var filtered []*drive.File
+	for _, file := range files {
+		if mimeType != "" && file.MimeType != mimeType {
+			continue
+		}
+		if ownedByMe && !isOwner(file, subject) {
+			continue
+		}
+		filtered = append(filtered, file)
+	}
+	return filtered
+}
+
+// isOwner returns true if the email is one of the owners of the file
+func isOwner(file *drive.File, email string) bool {
+	for _, owner := range file.Owners {
+		if owner.EmailAddress == email {
+			return true
+		}
+	}
+	return false
+}
+
+// requestSubject returns the user impersonated by the request or an empty string
+func requestSubject(r *http.Request) string {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if strings.HasPrefix(token, subjectTokenPrefix) {
+		return strings.TrimPrefix(token, subjectTokenPrefix)
+	}
+	return ""
+}
+
+// jwtSubject returns the subject of a JWT assertion without verifying it
+func jwtSubject(assertion string) string {
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
@@ -0,0 +1,27 @@
+package FakeGoogleAPI
+
+import (
+	"net/http"
+)
+
+// serveGroupsSettings answers the Groups Settings API requests, the segments follow "groups/v1/groups"
+func (receiver *Server) serveGroupsSettings(w http.ResponseWriter, r *http.Request, segments []string) bool {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+
+	if len(segments) != 1 || r.Method != http.MethodGet {
+		return false
+	}
+	group := receiver.tenant.findGroup(segments[0])
+	if group == nil {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: groupUniqueId")
+		return true
+	}
+	settings := receiver.tenant.GroupSettings[group.Email]
+	if settings == nil {
+		writeError(w, http.StatusNotFound, "notFound", "Resource Not Found: settings")
+		return true
+	}
+	writeJSON(w, settings)
+	return true
+}
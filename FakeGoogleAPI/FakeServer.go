@@ -0,0 +1,269 @@
+package FakeGoogleAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server This is an in-process fake of the Google APIs called by the GoogleAPI wrappers
+type Server struct {
+	// URL is the base URL of the fake server
+	URL string
+	// PageSize is the maximum number of items returned by a list call, requests may ask for less
+	PageSize int
+	// Bandwidth is the number of bytes per second at which every response is sent, zero sends them at once.
+	// It simulates the time spent transferring the responses over the network, for example to benchmark the field masks.
+	Bandwidth int64
+
+	server   *httptest.Server
+	mu       sync.Mutex
+	tenant   *Tenant
+	faults   []*Fault
+	requests map[string]int
+	bytes    map[string]int64
+	uploads  map[string]*upload
+}
+
+// Fault This is an error returned by the fake server instead of the response of a request
+type Fault struct {
+	// Path is the prefix of the request path the fault applies to, for example "/admin/directory/v1/users"
+	Path string
+	// Code is the HTTP status code of the error
+	Code int
+	// Reason is the reason of the error, for example "rateLimitExceeded"
+	Reason string
+	// RetryAfter is sent as the Retry-After header when it is set
+	RetryAfter string
+	// Times is the number of requests that fail, a negative value fails every request
+	Times int
+}
+
+// NewServer starts a new fake server serving the tenant
+func NewServer(tenant *Tenant) *Server {
+	if tenant == nil {
+		tenant = &Tenant{}
+	}
+	newServer := &Server{
+		PageSize: 100,
+		tenant:   tenant,
+		requests: make(map[string]int),
+		bytes:    make(map[string]int64),
+		uploads:  make(map[string]*upload),
+	}
+	newServer.server = httptest.NewServer(http.HandlerFunc(newServer.serveHTTP))
+	newServer.URL = newServer.server.URL
+	return newServer
+}
+
+// Close shuts the fake server down
+func (receiver *Server) Close() {
+	receiver.server.Close()
+}
+
+// Client returns an HTTP client that sends every request to the fake server whatever its host
+func (receiver *Server) Client() *http.Client {
+	target, _ := url.Parse(receiver.URL)
+	return &http.Client{Transport: &rewriteTransport{target: target, base: receiver.server.Client().Transport}}
+}
+
+// Tenant returns the data served by the fake server, it must not be modified while requests are in flight
+func (receiver *Server) Tenant() *Tenant {
+	return receiver.tenant
+}
+
+// InjectFault makes the requests matching the fault fail
+func (receiver *Server) InjectFault(fault *Fault) {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.faults = append(receiver.faults, fault)
+}
+
+// InjectError makes the next requests to the path fail with the code and reason
+func (receiver *Server) InjectError(path string, code int, reason string, times int) {
+	receiver.InjectFault(&Fault{Path: path, Code: code, Reason: reason, Times: times})
+}
+
+// Requests returns the number of requests received for paths starting with the prefix
+func (receiver *Server) Requests(prefix string) int {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	count := 0
+	for path, n := range receiver.requests {
+		if strings.HasPrefix(path, prefix) {
+			count += n
+		}
+	}
+	return count
+}
+
+// Bytes returns the number of bytes of the bodies of the responses sent for paths starting with the prefix, after the fields filtering
+func (receiver *Server) Bytes(prefix string) int64 {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	var count int64
+	for path, n := range receiver.bytes {
+		if strings.HasPrefix(path, prefix) {
+			count += n
+		}
+	}
+	return count
+}
+
+// rewriteTransport This is a RoundTripper that sends the requests to the fake server
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+// RoundTrip rewrites the scheme and host of the request
+func (receiver *rewriteTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	rewritten := request.Clone(request.Context())
+	rewritten.URL.Scheme = receiver.target.Scheme
+	rewritten.URL.Host = receiver.target.Host
+	rewritten.Host = ""
+	return receiver.base.RoundTrip(rewritten)
+}
+
+// serveHTTP records the request, applies the faults and routes the request to the API handlers.
+// The responses keep the fields selected by the fields parameter of the request, and their size is recorded.
+func (receiver *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if fault := receiver.nextFault(path); fault != nil {
+		if fault.RetryAfter != "" {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		writeError(w, fault.Code, fault.Reason, fmt.Sprintf("injected error for %s", path))
+		return
+	}
+	recorder := newResponseRecorder()
+	defer func(w http.ResponseWriter) {
+		n := recorder.flush(w, r)
+		receiver.mu.Lock()
+		receiver.bytes[path] += int64(n)
+		receiver.mu.Unlock()
+		if receiver.Bandwidth > 0 {
+			time.Sleep(time.Duration(int64(n) * int64(time.Second) / receiver.Bandwidth))
+		}
+	}(w)
+	w = recorder
+
+	segments := splitPath(r.URL.EscapedPath())
+	var handled bool
+	switch {
+	case hasPrefix(segments, "token"):
+		receiver.serveToken(w, r)
+		handled = true
+	case hasPrefix(segments, "admin", "directory", "v1"):
+		handled = receiver.serveDirectory(w, r, segments[3:])
+	case hasPrefix(segments, "groups", "v1", "groups"):
+		handled = receiver.serveGroupsSettings(w, r, segments[3:])
+	case hasPrefix(segments, "upload", "drive", "v3"):
+		handled = receiver.serveUpload(w, r, segments[3:])
+	case hasPrefix(segments, "drive", "v3"):
+		handled = receiver.serveDrive(w, r, segments[2:])
+	case hasPrefix(segments, "v1", "projects"):
+		handled = receiver.serveCloud(w, r, segments[1:])
+	}
+	if !handled {
+		writeError(w, http.StatusNotFound, "notFound", fmt.Sprintf("%s %s is not implemented by the fake server", r.Method, path))
+	}
+}
+
+// nextFault records the request and returns the fault to apply to it, if any
+func (receiver *Server) nextFault(path string) *Fault {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.requests[path]++
+	for _, fault := range receiver.faults {
+		if fault.Times != 0 && strings.HasPrefix(path, fault.Path) {
+			if fault.Times > 0 {
+				fault.Times--
+			}
+			return fault
+		}
+	}
+	return nil
+}
+
+// serveToken answers the OAuth2 token requests of the JWT and refresh token flows
+func (receiver *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	token := "fake-access-token"
+	if subject := jwtSubject(r.PostForm.Get("assertion")); subject != "" {
+		token = subjectTokenPrefix + subject
+	}
+	writeJSON(w, map[string]any{"access_token": token, "token_type": "Bearer", "expires_in": 3600})
+}
+
+// splitPath splits the unescaped segments of a path
+func splitPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// hasPrefix returns true if the path segments start with the prefix
+func hasPrefix(segments []string, prefix ...string) bool {
+	if len(segments) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if segments[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// page returns the bounds of the page requested with the page token and the size parameter, and the next page token
+func (receiver *Server) page(r *http.Request, sizeParameter string, total int) (int, int, string) {
+	start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+	size := receiver.PageSize
+	if requested, err := strconv.Atoi(r.URL.Query().Get(sizeParameter)); err == nil && requested > 0 && requested < size {
+		size = requested
+	}
+	if start > total {
+		start = total
+	}
+	end := start + size
+	if end >= total {
+		return start, total, ""
+	}
+	return start, end, strconv.Itoa(end)
+}
+
+// writeJSON writes the value as the JSON response
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeError writes an error in the format returned by the Google APIs
+func writeError(w http.ResponseWriter, code int, reason, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    code,
+			"message": message,
+			"errors":  []map[string]any{{"reason": reason, "message": message}},
+		},
+	})
+}
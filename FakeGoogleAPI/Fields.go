@@ -0,0 +1,180 @@
+package FakeGoogleAPI
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// fieldMask This is a parsed partial response mask, a nil sub-mask selects the whole field
+type fieldMask map[string]fieldMask
+
+// parseFieldMask parses the fields parameter of a request, like "nextPageToken,users(id,name/fullName)".
+// It returns nil for an empty mask or for "*", both select the whole response.
+func parseFieldMask(fields string) (fieldMask, error) {
+	if fields == "" || fields == "*" {
+		return nil, nil
+	}
+	parser := &fieldParser{fields: fields}
+	mask, err := parser.list()
+	if err != nil {
+		return nil, err
+	}
+	if parser.position < len(fields) {
+		return nil, fmt.Errorf("invalid field mask %q: unexpected %q at %d", fields, fields[parser.position], parser.position)
+	}
+	return mask, nil
+}
+
+// fieldParser This is the state of the parsing of a field mask
+type fieldParser struct {
+	fields   string
+	position int
+}
+
+// list parses the comma separated paths up to the end of the mask or a closing parenthesis
+func (receiver *fieldParser) list() (fieldMask, error) {
+	mask := fieldMask{}
+	for {
+		name, sub, err := receiver.path()
+		if err != nil {
+			return nil, err
+		}
+		mask.merge(name, sub)
+		if receiver.position >= len(receiver.fields) || receiver.fields[receiver.position] != ',' {
+			return mask, nil
+		}
+		receiver.position++
+	}
+}
+
+// path parses a field name followed by a "/" sub-path or a parenthesized sub-selection
+func (receiver *fieldParser) path() (string, fieldMask, error) {
+	start := receiver.position
+	for receiver.position < len(receiver.fields) && !strings.ContainsRune(",/()", rune(receiver.fields[receiver.position])) {
+		receiver.position++
+	}
+	name := strings.TrimSpace(receiver.fields[start:receiver.position])
+	if name == "" {
+		return "", nil, fmt.Errorf("invalid field mask %q: missing field name at %d", receiver.fields, start)
+	}
+	if receiver.position >= len(receiver.fields) {
+		return name, nil, nil
+	}
+	switch receiver.fields[receiver.position] {
+	case '/':
+		receiver.position++
+		subName, sub, err := receiver.path()
+		if err != nil {
+			return "", nil, err
+		}
+		return name, fieldMask{subName: sub}, nil
+	case '(':
+		receiver.position++
+		sub, err := receiver.list()
+		if err != nil {
+			return "", nil, err
+		}
+		if receiver.position >= len(receiver.fields) || receiver.fields[receiver.position] != ')' {
+			return "", nil, fmt.Errorf("invalid field mask %q: missing closing parenthesis", receiver.fields)
+		}
+		receiver.position++
+		return name, sub, nil
+	}
+	return name, nil, nil
+}
+
+// merge adds a field to the mask, a field selected as a whole stays whole
+func (receiver fieldMask) merge(name string, sub fieldMask) {
+	existing, ok := receiver[name]
+	switch {
+	case !ok:
+		receiver[name] = sub
+	case existing == nil || sub == nil:
+		receiver[name] = nil
+	default:
+		for subName, subMask := range sub {
+			existing.merge(subName, subMask)
+		}
+	}
+}
+
+// apply returns the decoded JSON value with only the selected fields, the masks apply to every item of the arrays
+func (receiver fieldMask) apply(value any) any {
+	if receiver == nil {
+		return value
+	}
+	switch typed := value.(type) {
+	case map[string]any:
+		filtered := make(map[string]any)
+		for name, field := range typed {
+			if sub, ok := receiver[name]; ok {
+				filtered[name] = sub.apply(field)
+			} else if sub, ok := receiver["*"]; ok {
+				filtered[name] = sub.apply(field)
+			}
+		}
+		return filtered
+	case []any:
+		for i := range typed {
+			typed[i] = receiver.apply(typed[i])
+		}
+		return typed
+	}
+	return value
+}
+
+// responseRecorder This is a ResponseWriter holding the body of a response to filter it before it is sent
+type responseRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+// newResponseRecorder returns a new responseRecorder
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), code: http.StatusOK}
+}
+
+// Header returns the headers of the response
+func (receiver *responseRecorder) Header() http.Header {
+	return receiver.header
+}
+
+// Write appends to the body of the response
+func (receiver *responseRecorder) Write(data []byte) (int, error) {
+	return receiver.body.Write(data)
+}
+
+// WriteHeader records the status code of the response
+func (receiver *responseRecorder) WriteHeader(code int) {
+	receiver.code = code
+}
+
+// flush writes the response keeping only the fields of the mask of the request, and returns the number of bytes of the body.
+// Every JSON response is decoded and encoded again, with or without a mask, so that the masks only change the size of the responses.
+// The errors and the responses that are not JSON are written as they are.
+func (receiver *responseRecorder) flush(w http.ResponseWriter, r *http.Request) int {
+	body := receiver.body.Bytes()
+	if receiver.code == http.StatusOK && strings.HasPrefix(receiver.header.Get("Content-Type"), "application/json") {
+		mask, err := parseFieldMask(r.URL.Query().Get("fields"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalidParameter", err.Error())
+			return 0
+		}
+		var value any
+		if err := json.Unmarshal(body, &value); err == nil {
+			if filtered, err := json.Marshal(mask.apply(value)); err == nil {
+				body = filtered
+			}
+		}
+	}
+	for key, values := range receiver.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(receiver.code)
+	n, _ := w.Write(body)
+	return n
+}
@@ -0,0 +1,261 @@
+package FakeGoogleAPI
+
+import (
+	"fmt"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/iam/v1"
+)
+
+// SyntheticSize This is the number of entities of a generated tenant
+type SyntheticSize struct {
+	Users int
+	// TokensPerUser is the number of OAuth tokens of every user
+	TokensPerUser int
+	Groups        int
+	// MembersPerGroup is the number of users of every group, capped to the number of users
+	MembersPerGroup int
+	// Files are owned by the users in turn, every file has PermissionsPerFile permissions
+	Files              int
+	PermissionsPerFile int
+	Drives             int
+	// Projects have ServiceAccountsPerProject service accounts each
+	Projects                  int
+	ServiceAccountsPerProject int
+}
+
+// SyntheticDomain This is the domain of the users and groups of a generated tenant
+const SyntheticDomain = "synthetic.example.com"
+
+// NewSyntheticTenant generates a tenant of the given size, for example to benchmark the wrappers on a large customer.
+// The entities are filled with the fields the Google APIs return for real accounts, so that the size of the responses
+// with and without a field mask is realistic, and the same size always generates the same tenant.
+func NewSyntheticTenant(size SyntheticSize) *Tenant {
+	tenant := &Tenant{
+		Members:         make(map[string][]*directory.Member),
+		Tokens:          make(map[string][]*directory.Token),
+		Permissions:     make(map[string][]*drive.Permission),
+		ServiceAccounts: make(map[string][]*iam.ServiceAccount),
+	}
+	// The files and permissions of a tenant without users are given to the first synthetic user
+	users := size.Users
+	if users < 1 {
+		users = 1
+	}
+
+	for i := 0; i < size.Users; i++ {
+		user := syntheticUser(i)
+		tenant.Users = append(tenant.Users, user)
+		for j := 0; j < size.TokensPerUser; j++ {
+			tenant.Tokens[user.PrimaryEmail] = append(tenant.Tokens[user.PrimaryEmail], syntheticToken(user, j))
+		}
+	}
+
+	for i := 0; i < size.Groups; i++ {
+		group := &directory.Group{
+			Id:                 fmt.Sprintf("group%05d", i),
+			Email:              fmt.Sprintf("group%05d@%s", i, SyntheticDomain),
+			Name:               fmt.Sprintf("Synthetic Group %d", i),
+			Description:        fmt.Sprintf("Mailing list number %d of the synthetic tenant, used by the benchmarks of the field masks", i),
+			Aliases:            []string{fmt.Sprintf("list%05d@%s", i, SyntheticDomain)},
+			NonEditableAliases: []string{fmt.Sprintf("group%05d@%s.test-google-a.com", i, SyntheticDomain)},
+			AdminCreated:       i%2 == 0,
+			Etag:               syntheticEtag("group", i),
+			Kind:               "admin#directory#group",
+		}
+		for j := 0; j < size.MembersPerGroup && j < size.Users; j++ {
+			user := tenant.Users[(i+j)%size.Users]
+			role := "MEMBER"
+			if j == 0 {
+				role = "OWNER"
+			}
+			tenant.Members[group.Email] = append(tenant.Members[group.Email], &directory.Member{
+				Id:               user.Id,
+				Email:            user.PrimaryEmail,
+				Role:             role,
+				Type:             "USER",
+				Status:           "ACTIVE",
+				DeliverySettings: "ALL_MAIL",
+				Etag:             syntheticEtag("member", i*size.MembersPerGroup+j),
+				Kind:             "admin#directory#member",
+			})
+		}
+		group.DirectMembersCount = int64(len(tenant.Members[group.Email]))
+		tenant.Groups = append(tenant.Groups, group)
+	}
+
+	for i := 0; i < size.Files; i++ {
+		owner := fmt.Sprintf("user%05d@%s", i%users, SyntheticDomain)
+		file := syntheticFile(i, owner)
+		tenant.Files = append(tenant.Files, file)
+		for j := 0; j < size.PermissionsPerFile; j++ {
+			tenant.Permissions[file.Id] = append(tenant.Permissions[file.Id], syntheticPermission(i, j, users))
+		}
+	}
+
+	for i := 0; i < size.Drives; i++ {
+		sharedDrive := &drive.Drive{
+			Id:                  fmt.Sprintf("drive%05d", i),
+			Name:                fmt.Sprintf("Synthetic Shared Drive %d", i),
+			Kind:                "drive#drive",
+			CreatedTime:         "2021-03-04T10:00:00.000Z",
+			ColorRgb:            "#4986e7",
+			ThemeId:             "abacus",
+			BackgroundImageLink: fmt.Sprintf("https://ssl.gstatic.com/team_drive_themes/abacus_background.jpg?drive=%d", i),
+			Capabilities: &drive.DriveCapabilities{
+				CanAddChildren: true, CanComment: true, CanCopy: true, CanDownload: true, CanEdit: true,
+				CanListChildren: true, CanManageMembers: true, CanReadRevisions: true, CanShare: true,
+			},
+			Restrictions: &drive.DriveRestrictions{DomainUsersOnly: true, DriveMembersOnly: true},
+		}
+		tenant.Drives = append(tenant.Drives, sharedDrive)
+		for j := 0; j < size.PermissionsPerFile; j++ {
+			tenant.Permissions[sharedDrive.Id] = append(tenant.Permissions[sharedDrive.Id], syntheticPermission(i, j, users))
+		}
+	}
+
+	for i := 0; i < size.Projects; i++ {
+		project := &cloudresourcemanager.Project{
+			ProjectId:      fmt.Sprintf("synthetic-project-%05d", i),
+			ProjectNumber:  int64(100000000000 + i),
+			Name:           fmt.Sprintf("Synthetic Project %d", i),
+			LifecycleState: "ACTIVE",
+			CreateTime:     "2020-01-02T03:04:05.000Z",
+			Labels:         map[string]string{"environment": "production", "team": fmt.Sprintf("team-%d", i%10)},
+			Parent:         &cloudresourcemanager.ResourceId{Type: "folder", Id: fmt.Sprintf("%d", 500000+i%10)},
+		}
+		tenant.Projects = append(tenant.Projects, project)
+		for j := 0; j < size.ServiceAccountsPerProject; j++ {
+			tenant.ServiceAccounts[project.ProjectId] = append(tenant.ServiceAccounts[project.ProjectId], &iam.ServiceAccount{
+				Name:           fmt.Sprintf("projects/%s/serviceAccounts/sa-%d@%s.iam.gserviceaccount.com", project.ProjectId, j, project.ProjectId),
+				Email:          fmt.Sprintf("sa-%d@%s.iam.gserviceaccount.com", j, project.ProjectId),
+				DisplayName:    fmt.Sprintf("Service account %d", j),
+				Description:    "Service account of the synthetic tenant",
+				ProjectId:      project.ProjectId,
+				UniqueId:       fmt.Sprintf("1%020d", i*1000+j),
+				Oauth2ClientId: fmt.Sprintf("1%020d", i*1000+j),
+				Etag:           syntheticEtag("serviceAccount", i*1000+j),
+			})
+		}
+	}
+	return tenant
+}
+
+// syntheticUser returns the user of the given index with the profile fields of a real account
+func syntheticUser(i int) *directory.User {
+	email := fmt.Sprintf("user%05d@%s", i, SyntheticDomain)
+	orgUnits := []string{"/", "/Engineering", "/Engineering/Platform", "/Sales", "/Support"}
+	return &directory.User{
+		Id:                         fmt.Sprintf("1%020d", i),
+		PrimaryEmail:               email,
+		Name:                       &directory.UserName{GivenName: fmt.Sprintf("Given%d", i), FamilyName: fmt.Sprintf("Family%d", i), FullName: fmt.Sprintf("Given%d Family%d", i, i)},
+		OrgUnitPath:                orgUnits[i%len(orgUnits)],
+		IsAdmin:                    i%500 == 0,
+		IsDelegatedAdmin:           i%100 == 1,
+		Suspended:                  i%50 == 2,
+		IsMailboxSetup:             true,
+		IsEnrolledIn2Sv:            i%3 != 0,
+		IsEnforcedIn2Sv:            i%6 == 1,
+		AgreedToTerms:              true,
+		IncludeInGlobalAddressList: true,
+		CreationTime:               "2019-05-06T07:08:09.000Z",
+		LastLoginTime:              fmt.Sprintf("2024-%02d-%02dT10:00:00.000Z", i%12+1, i%28+1),
+		CustomerId:                 "C0synthetic",
+		Aliases:                    []string{fmt.Sprintf("alias%05d@%s", i, SyntheticDomain)},
+		NonEditableAliases:         []string{fmt.Sprintf("user%05d@%s.test-google-a.com", i, SyntheticDomain)},
+		ThumbnailPhotoUrl:          fmt.Sprintf("https://lh3.googleusercontent.com/a-/synthetic-photo-of-user-%05d=s96-c", i),
+		ThumbnailPhotoEtag:         syntheticEtag("photo", i),
+		RecoveryEmail:              fmt.Sprintf("user%05d@personal.example.org", i),
+		Emails: []map[string]any{
+			{"address": email, "primary": true},
+			{"address": fmt.Sprintf("alias%05d@%s", i, SyntheticDomain)},
+		},
+		Phones:        []map[string]any{{"value": fmt.Sprintf("+1555%07d", i), "type": "work"}},
+		Addresses:     []map[string]any{{"type": "work", "formatted": fmt.Sprintf("%d Synthetic Street, Washington, DC 20001", i)}},
+		Organizations: []map[string]any{{"title": "Engineer", "department": orgUnits[i%len(orgUnits)], "costCenter": fmt.Sprintf("CC%03d", i%100), "primary": true}},
+		Languages:     []map[string]any{{"languageCode": "en", "preference": "preferred"}},
+		Etag:          syntheticEtag("user", i),
+		Kind:          "admin#directory#user",
+	}
+}
+
+// syntheticToken returns the token of the user for the app of the given index
+func syntheticToken(user *directory.User, j int) *directory.Token {
+	return &directory.Token{
+		ClientId:    fmt.Sprintf("%d-synthetic.apps.googleusercontent.com", 1000+j),
+		DisplayText: fmt.Sprintf("Synthetic App %d", j),
+		Scopes: []string{
+			"openid",
+			"https://www.googleapis.com/auth/userinfo.email",
+			"https://www.googleapis.com/auth/userinfo.profile",
+			"https://www.googleapis.com/auth/drive.readonly",
+			"https://www.googleapis.com/auth/calendar.events",
+		},
+		UserKey:   user.Id,
+		NativeApp: j%2 == 1,
+		Etag:      syntheticEtag("token", j),
+		Kind:      "admin#directory#token",
+	}
+}
+
+// syntheticFile returns the file of the given index owned by the owner
+func syntheticFile(i int, owner string) *drive.File {
+	id := fmt.Sprintf("file%07d", i)
+	mimeType := "application/vnd.google-apps.document"
+	if i%10 == 0 {
+		mimeType = "application/vnd.google-apps.script"
+	}
+	return &drive.File{
+		Id:             id,
+		Name:           fmt.Sprintf("Synthetic File %d", i),
+		MimeType:       mimeType,
+		Kind:           "drive#file",
+		CreatedTime:    "2022-01-02T03:04:05.000Z",
+		ModifiedTime:   "2023-06-07T08:09:10.000Z",
+		ViewedByMeTime: "2023-06-08T08:09:10.000Z",
+		Shared:         i%3 == 0,
+		Starred:        i%7 == 0,
+		Parents:        []string{fmt.Sprintf("folder%05d", i%100)},
+		Spaces:         []string{"drive"},
+		Version:        int64(i%50 + 1),
+		WebViewLink:    "https://docs.google.com/document/d/" + id + "/edit?usp=drivesdk",
+		IconLink:       "https://drive-thirdparty.googleusercontent.com/16/type/" + mimeType,
+		ThumbnailLink:  "https://lh3.googleusercontent.com/drive-storage/synthetic-thumbnail-of-" + id + "=s220",
+		Owners: []*drive.User{{
+			EmailAddress: owner,
+			DisplayName:  "Owner of " + id,
+			Kind:         "drive#user",
+			Me:           true,
+			PermissionId: fmt.Sprintf("%020d", i),
+			PhotoLink:    "https://lh3.googleusercontent.com/a-/synthetic-photo=s64",
+		}},
+		Capabilities: &drive.FileCapabilities{
+			CanAddChildren: false, CanComment: true, CanCopy: true, CanDelete: true, CanDownload: true, CanEdit: true,
+			CanListChildren: false, CanModifyContent: true, CanReadRevisions: true, CanRename: true, CanShare: true, CanTrash: true,
+		},
+	}
+}
+
+// syntheticPermission returns the permission of the given index of the file or shared drive of the given index
+func syntheticPermission(i, j, users int) *drive.Permission {
+	roles := []string{"owner", "writer", "commenter", "reader", "organizer"}
+	email := fmt.Sprintf("user%05d@%s", (i+j)%users, SyntheticDomain)
+	return &drive.Permission{
+		Id:           fmt.Sprintf("%020d", i*100+j),
+		Type:         "user",
+		Role:         roles[j%len(roles)],
+		EmailAddress: email,
+		DisplayName:  "Display name of " + email,
+		PhotoLink:    "https://lh3.googleusercontent.com/a-/synthetic-photo=s64",
+		Kind:         "drive#permission",
+		PermissionDetails: []*drive.PermissionPermissionDetails{
+			{PermissionType: "file", Role: roles[j%len(roles)], Inherited: false},
+		},
+	}
+}
+
+// syntheticEtag returns a stable etag of the entity of the given kind and index
+func syntheticEtag(kind string, i int) string {
+	return fmt.Sprintf("\"synthetic-%s-%08x-etag-value-of-the-fake-tenant\"", kind, i*2654435761)
+}
@@ -0,0 +1,76 @@
+package FakeGoogleAPI
+
+import (
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/iam/v1"
+)
+
+// Tenant This is the Workspace and Google Cloud data served by the fake server
+type Tenant struct {
+	Users  []*directory.User
+	Groups []*directory.Group
+	// Members are the members of the groups by group email
+	Members map[string][]*directory.Member
+	// Tokens are the OAuth tokens of the users by user email
+	Tokens map[string][]*directory.Token
+	// ASPs and VerificationCodes are the application-specific passwords and backup verification codes of the users by user email
+	ASPs              map[string][]*directory.Asp
+	VerificationCodes map[string][]*directory.VerificationCode
+	// Roles, Privileges and RoleAssignments are the admin roles of the customer
+	Roles           []*directory.Role
+	Privileges      []*directory.Privilege
+	RoleAssignments []*directory.RoleAssignment
+	// MobileDevices and ChromeOSDevices are the devices of the customer
+	MobileDevices   []*directory.MobileDevice
+	ChromeOSDevices []*directory.ChromeOsDevice
+	// Domains and DomainAliases are the domains of the customer, Buildings and CalendarResources its resources
+	Domains           []*directory.Domains
+	DomainAliases     []*directory.DomainAlias
+	Buildings         []*directory.Building
+	CalendarResources []*directory.CalendarResource
+	// OrgUnits are the organizational units of the customer, without the root unit
+	OrgUnits []*directory.OrgUnit
+	// GroupSettings are the settings of the groups by group email
+	GroupSettings map[string]*groupssettings.Groups
+
+	Drives []*drive.Drive
+	// Files are listed by the files.list call, "'me' in owners" only returns the files of the impersonated user
+	Files []*drive.File
+	// Permissions are the permissions of the files and shared drives by id
+	Permissions map[string][]*drive.Permission
+	// Uploads are the files uploaded to the fake server
+	Uploads []*UploadedFile
+
+	Projects []*cloudresourcemanager.Project
+	// ServiceAccounts are the service accounts of the projects by project id
+	ServiceAccounts map[string][]*iam.ServiceAccount
+}
+
+// UploadedFile This is a file uploaded to the fake server
+type UploadedFile struct {
+	MetaData *drive.File
+	Data     []byte
+}
+
+// findUser returns the user with the given id or email
+func (receiver *Tenant) findUser(userKey string) *directory.User {
+	for _, user := range receiver.Users {
+		if user.Id == userKey || user.PrimaryEmail == userKey {
+			return user
+		}
+	}
+	return nil
+}
+
+// findGroup returns the group with the given id or email
+func (receiver *Tenant) findGroup(groupKey string) *directory.Group {
+	for _, group := range receiver.Groups {
+		if group.Id == groupKey || group.Email == groupKey {
+			return group
+		}
+	}
+	return nil
+}
@@ -2,20 +2,21 @@ package GoogleAPI
 
 import (
 	"context"
-	"fmt"
 	"google.golang.org/api/cloudresourcemanager/v1"
 	"google.golang.org/api/option"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 )
 
 // CloudResourceManagerAPI This is the struct that is used to interact with the Cloud Resource Manager API
 type CloudResourceManagerAPI struct {
-	Client    *cloudresourcemanager.Service
-	SleepTime int
-	MaxTries  int
+	Client *cloudresourcemanager.Service
+	Retry  *RetryPolicy
+	// Ctx is the context of every request, cancelling it stops the pagination and the retries
+	Ctx context.Context
+	// Fields are the field masks of the calls, the zero value sends the minimal masks
+	Fields FieldMasks
 }
 
 // NewCloudResourceManagerAPI returns a new CloudResourceManagerAPI
@@ -26,12 +27,13 @@ func NewCloudResourceManagerAPI(client *http.Client, sleepTimer int, ctx context
 	// Create a Firestore client
 	firestoreClient, err := cloudresourcemanager.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		log.Fatalf("Failed to create client: %v", err)
+		log.Println(err.Error())
+		panic(err)
 	}
 	// Set the Firestore client
 	newAPI.Client = firestoreClient
-	newAPI.SleepTime = sleepTimer
-	newAPI.MaxTries = 10
+	newAPI.Retry = NewRetryPolicy(time.Duration(sleepTimer) * time.Second)
+	newAPI.Ctx = ctx
 	return newAPI
 }
 
@@ -42,42 +44,42 @@ func (receiver *CloudResourceManagerAPI) GetAllProjects() ([]*cloudresourcemanag
 	// create a page token to hold the next page token
 	var pageToken string
 
-	// Set the number of tries
-	tryCounter := 0
 	// loop through all pages
 	for {
 		// get the next page of projects
-		response, err := receiver.Client.Projects.List().
-			Fields("*").
-			PageToken(pageToken).Do()
+		projects, nextPageToken, err := receiver.GetProjectsPage(pageToken)
 		if err != nil {
-			if strings.Contains(err.Error(), "quota") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), time.Duration(receiver.SleepTime))
-				time.Sleep(time.Duration(receiver.SleepTime) * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") && tryCounter < receiver.MaxTries {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				tryCounter++
-				continue
-			} else {
-				return nil, err
-			}
+			return nil, err
 		}
 
 		// append the projects to the slice
-		allProjects = append(allProjects, response.Projects...)
+		allProjects = append(allProjects, projects...)
 		log.Printf("Projects thus far: %d", len(allProjects))
 
 		// if there is no next page, break
-		if response.NextPageToken == "" {
+		if nextPageToken == "" {
 			break
 		}
 
 		// set the page token to the next page token
-		pageToken = response.NextPageToken
+		pageToken = nextPageToken
 	}
 
 	// return all projects
 	return allProjects, nil
 }
+
+// GetProjectsPage returns a single page of projects and the token of the next page, empty on the last page
+func (receiver *CloudResourceManagerAPI) GetProjectsPage(pageToken string) ([]*cloudresourcemanager.Project, string, error) {
+	var response *cloudresourcemanager.ListProjectsResponse
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		response, err = receiver.Client.Projects.List().
+			Fields(receiver.Fields.Get(ProjectsListCall)).
+			PageToken(pageToken).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return response.Projects, response.NextPageToken, nil
+}
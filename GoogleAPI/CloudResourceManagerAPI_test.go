@@ -0,0 +1,25 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetAllProjects(t *testing.T) {
+	server := newTestServer(t)
+	server.PageSize = 1
+	crmAPI := GoogleAPI.NewCloudResourceManagerAPI(server.Client(), 0, context.Background())
+	crmAPI.Retry.InitialBackoff = time.Millisecond
+	server.InjectError("/v1/projects", http.StatusTooManyRequests, "rateLimitExceeded", 1)
+
+	projects, err := crmAPI.GetAllProjects()
+	if err != nil {
+		t.Fatalf("GetAllProjects: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Errorf("GetAllProjects returned %d projects, want 2", len(projects))
+	}
+}
@@ -2,8 +2,7 @@ package GoogleAPI
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
 	directory "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/option"
 	"log"
@@ -20,15 +19,25 @@ var AdminScopes = []string{
 	directory.AdminDirectoryGroupMemberReadonlyScope,
 	directory.AdminDirectoryResourceCalendarReadonlyScope,
 	directory.AdminDirectoryUserSecurityScope,
+	directory.AdminDirectoryRolemanagementReadonlyScope,
+	directory.AdminDirectoryOrgunitReadonlyScope,
+	directory.AdminDirectoryDeviceMobileReadonlyScope,
+	directory.AdminDirectoryDeviceChromeosReadonlyScope,
+	directory.AdminDirectoryDomainReadonlyScope,
 }
 
 // DirectoryAPI This is the struct that is used to interact with the Admin SDK
 type DirectoryAPI struct {
 	DirectoryService *directory.Service
 	Customer         string
-	SleepTime        int
 	Jobs             *sync.WaitGroup
-	MaxTries         int
+	Retry            *RetryPolicy
+	// Concurrency is the number of users processed at the same time by the methods looping over users
+	Concurrency int
+	// Ctx is the context of every request, cancelling it stops the pagination, the workers and the retries
+	Ctx context.Context
+	// Fields are the field masks of the listings, the zero value sends the minimal masks
+	Fields FieldMasks
 }
 
 // NewDirectoryAPI  This method is used to create a new DirectoryAPI
@@ -49,14 +58,17 @@ func NewDirectoryAPI(client *http.Client, sleepTime int, ctx context.Context) *D
 	// Create a new WaitGroup
 	newAdminAPI.Jobs = &sync.WaitGroup{}
 
-	// Set the sleep time
+	// Set the customer
 	newAdminAPI.Customer = "my_customer"
 
-	// Set the sleep time
-	newAdminAPI.SleepTime = sleepTime
+	// Set the retry policy
+	newAdminAPI.Retry = NewRetryPolicy(time.Duration(sleepTime) * time.Second)
 
-	// Set the max tries
-	newAdminAPI.MaxTries = 10
+	// Set the concurrency
+	newAdminAPI.Concurrency = 100
+
+	// Set the context of the requests
+	newAdminAPI.Ctx = ctx
 
 	// Return the new DriveAPI
 	return newAdminAPI
@@ -70,55 +82,19 @@ func (receiver *DirectoryAPI) QueryUsers(q string) ([]*directory.User, error) {
 	// Create a new userList
 	var userList []*directory.User
 
-	// Create a new request
-	request := receiver.
-		DirectoryService.
-		Users.
-		List().
-		Fields("*").
-		PageToken(pt).
-		Customer(receiver.Customer)
-
-	// If there is a query add it to the request
-	if q != "" {
-		request.Query(q)
-	}
-
-	// A counter for the number of tries
-	tryCounter := 0
 	// Loop through all pages
 	for {
-		response, err := receiver.
-			DirectoryService.
-			Users.
-			List().
-			Query(q).
-			Fields("*").
-			PageToken(pt).
-			Customer(receiver.Customer).
-			Do()
-
+		users, nextPageToken, err := receiver.QueryUsersPage(q, pt)
 		if err != nil {
-			if strings.Contains(err.Error(), "quota") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), time.Duration(receiver.SleepTime))
-				time.Sleep(time.Duration(receiver.SleepTime) * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") && tryCounter < receiver.MaxTries {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				tryCounter++
-				continue
-			} else {
-				return nil, err
-			}
+			return nil, err
 		}
 
 		// Pass the current users to the userList
-		userList = append(userList, response.Users...)
+		userList = append(userList, users...)
 		log.Printf("Users thus far: %d", len(userList))
 
 		// Check if there is a next page and add it to the page token
-		pt = response.NextPageToken
+		pt = nextPageToken
 
 		if pt == "" {
 			break
@@ -129,6 +105,33 @@ func (receiver *DirectoryAPI) QueryUsers(q string) ([]*directory.User, error) {
 	return userList, nil
 }
 
+// QueryUsersPage This method is used to get a single page of users and the token of the next page, empty on the last page
+func (receiver *DirectoryAPI) QueryUsersPage(q, pageToken string) ([]*directory.User, string, error) {
+	// Create a new request
+	request := receiver.
+		DirectoryService.
+		Users.
+		List().
+		Fields(receiver.Fields.Get(UsersListCall)).
+		Customer(receiver.Customer).
+		PageToken(pageToken)
+
+	// If there is a query add it to the request
+	if q != "" {
+		request.Query(q)
+	}
+
+	var response *directory.Users
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		response, err = request.Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return response.Users, response.NextPageToken, nil
+}
+
 // QueryGroups This method is used to get a list of groups
 func (receiver *DirectoryAPI) QueryGroups(q string) ([]*directory.Group, error) {
 	// Set the page token to an empty string
@@ -137,43 +140,21 @@ func (receiver *DirectoryAPI) QueryGroups(q string) ([]*directory.Group, error)
 	// Create a new groupsList
 	var groupsList []*directory.Group
 
-	// Create a new page
-	page := receiver.DirectoryService.Groups.
-		List().
-		Customer(receiver.Customer).
-		SortOrder("ASCENDING").
-		Fields("*")
-
-	// If there is a query add it to the page
-	if q != "" {
-		page.Query(q)
-	}
-
 	// Loop through all pages
 	for {
 		// Get the groups
-		response, err := page.PageToken(pt).Do()
+		groups, nextPageToken, err := receiver.QueryGroupsPage(q, pt)
 		if err != nil {
-			if strings.Contains(err.Error(), "quota") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), time.Duration(receiver.SleepTime))
-				time.Sleep(time.Duration(receiver.SleepTime) * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				continue
-			} else {
-				return nil, err
-			}
+			return nil, err
 		}
 
 		// Pass the current groups to the groupsList
-		groupsList = append(groupsList, response.Groups...)
+		groupsList = append(groupsList, groups...)
 
 		log.Printf("Groups thus far: %d", len(groupsList))
 
 		// Check if there is a next page and add it to the page token
-		pt = response.NextPageToken
+		pt = nextPageToken
 		// if there is no next page break
 		if pt == "" {
 			break
@@ -184,13 +165,39 @@ func (receiver *DirectoryAPI) QueryGroups(q string) ([]*directory.Group, error)
 	return groupsList, nil
 }
 
+// QueryGroupsPage This method is used to get a single page of groups and the token of the next page, empty on the last page
+func (receiver *DirectoryAPI) QueryGroupsPage(q, pageToken string) ([]*directory.Group, string, error) {
+	// Create a new page
+	page := receiver.DirectoryService.Groups.
+		List().
+		Customer(receiver.Customer).
+		SortOrder("ASCENDING").
+		Fields(receiver.Fields.Get(GroupsListCall)).
+		PageToken(pageToken)
+
+	// If there is a query add it to the page
+	if q != "" {
+		page.Query(q)
+	}
+
+	var response *directory.Groups
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		response, err = page.Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return response.Groups, response.NextPageToken, nil
+}
+
 // GetGroupMembers This method pulls all the members of a group
 func (receiver *DirectoryAPI) GetGroupMembers(groupEmail *directory.Group, role string) ([]*directory.Member, error) {
 	request := receiver.
 		DirectoryService.
 		Members.
 		List(groupEmail.Email).
-		Fields("*")
+		Fields(receiver.Fields.Get(MembersListCall))
 
 	if role != "" {
 		request.Roles(role)
@@ -200,19 +207,13 @@ func (receiver *DirectoryAPI) GetGroupMembers(groupEmail *directory.Group, role
 
 	var memberList []*directory.Member
 	for {
-		response, err := request.PageToken(pt).Do()
+		var response *directory.Members
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			response, err = request.PageToken(pt).Context(receiver.Ctx).Do()
+			return err
+		})
 		if err != nil {
-			if strings.Contains(err.Error(), "quota") {
-				//fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), time.Duration(receiver.SleepTime))
-				time.Sleep(time.Duration(receiver.SleepTime) * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				continue
-			} else {
-				return nil, err
-			}
+			return nil, err
 		}
 
 		memberList = append(memberList, response.Members...)
@@ -235,24 +236,16 @@ func (receiver *DirectoryAPI) GetSubscriptions(memberEmail string) ([]*directory
 	// Loop through all pages
 	for {
 		// Get the groups
-		page, err := receiver.DirectoryService.Groups.
-			List().
-			UserKey(memberEmail).Fields("*").
-			PageToken(pageToken).Do()
-
-		// Check for errors
+		var page *directory.Groups
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			page, err = receiver.DirectoryService.Groups.
+				List().
+				UserKey(memberEmail).Fields(receiver.Fields.Get(GroupsListCall)).
+				PageToken(pageToken).Context(receiver.Ctx).Do()
+			return err
+		})
 		if err != nil {
-			if strings.Contains(err.Error(), "quota") {
-				//fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 2)
-				time.Sleep(2 * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				continue
-			} else {
-				return nil, err
-			}
+			return nil, err
 		}
 
 		// append the groups to the parents
@@ -270,26 +263,42 @@ func (receiver *DirectoryAPI) GetSubscriptions(memberEmail string) ([]*directory
 
 // GetUserTokens This method is used to get a list of tokens for a user
 func (receiver *DirectoryAPI) GetUserTokens(userEmail string) ([]*directory.Token, error) {
-	// A counter for the number of tries
-	tryCounter := 0
-	for {
-		res, err := receiver.DirectoryService.Tokens.List(userEmail).Fields("*").Do()
-		if err != nil {
-			if strings.Contains(err.Error(), "quota") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), time.Duration(receiver.SleepTime))
-				time.Sleep(time.Duration(receiver.SleepTime) * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") && tryCounter < receiver.MaxTries {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				tryCounter++
-				continue
-			} else {
-				return nil, err
-			}
-		}
-		return res.Items, nil
+	var res *directory.Tokens
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		res, err = receiver.DirectoryService.Tokens.List(userEmail).Fields(receiver.Fields.Get(TokensListCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Items, nil
+}
+
+// GetUserASPs This method is used to get the application-specific passwords of a user, they sign in without the second step of 2SV
+func (receiver *DirectoryAPI) GetUserASPs(userEmail string) ([]*directory.Asp, error) {
+	var res *directory.Asps
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		res, err = receiver.DirectoryService.Asps.List(userEmail).Fields(receiver.Fields.Get(AspsListCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Items, nil
+}
+
+// GetUserVerificationCodes This method is used to get the valid backup verification codes of a user, the codes already used are not returned.
+// Only the user id of every code is requested, whatever the field masks, so the codes themselves never reach the application.
+func (receiver *DirectoryAPI) GetUserVerificationCodes(userEmail string) ([]*directory.VerificationCode, error) {
+	var res *directory.VerificationCodes
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		res, err = receiver.DirectoryService.VerificationCodes.List(userEmail).Fields(VerificationCodesFields).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return res.Items, nil
 }
 
 // GoogleUser This struct is used to hold a user and their tokens
@@ -302,70 +311,414 @@ type GoogleUser struct {
 	Suspended        bool   `json:"is_suspended"`
 	IsMailboxSetup   bool   `json:"is_mailbox_setup"`
 	LastLoginTime    string `json:"last_login_time"`
-	Tokens           any    `json:"tokens"`
+	// CreationTime and OrgUnitPath are the creation time and the organizational unit of the user
+	CreationTime string `json:"creation_time"`
+	OrgUnitPath  string `json:"org_unit_path"`
+	// IsEnrolledIn2Sv and IsEnforcedIn2Sv are the two-step verification enrollment and enforcement of the user
+	IsEnrolledIn2Sv           bool `json:"is_enrolled_in_2sv"`
+	IsEnforcedIn2Sv           bool `json:"is_enforced_in_2sv"`
+	AgreedToTerms             bool `json:"agreed_to_terms"`
+	ChangePasswordAtNextLogin bool `json:"change_password_at_next_login"`
+	// HasRecoveryEmail and HasRecoveryPhone tell whether recovery contacts are set without exporting them
+	HasRecoveryEmail bool `json:"has_recovery_email"`
+	HasRecoveryPhone bool `json:"has_recovery_phone"`
+	// Tokens are the tokens of the user, nil when TokensErr is set
+	Tokens []*directory.Token `json:"tokens"`
+	// TokensErr is the error of the tokens request, the user has no tokens when it is set
+	TokensErr error `json:"-"`
+}
+
+// NewGoogleUser returns the GoogleUser of a user of the Directory API, without its tokens
+func NewGoogleUser(user *directory.User) *GoogleUser {
+	return &GoogleUser{
+		Id:                        user.Id,
+		PrimaryEmail:              user.PrimaryEmail,
+		Archived:                  user.Archived,
+		IsAdmin:                   user.IsAdmin,
+		IsDelegatedAdmin:          user.IsDelegatedAdmin,
+		LastLoginTime:             user.LastLoginTime,
+		Suspended:                 user.Suspended,
+		IsMailboxSetup:            user.IsMailboxSetup,
+		CreationTime:              user.CreationTime,
+		OrgUnitPath:               user.OrgUnitPath,
+		IsEnrolledIn2Sv:           user.IsEnrolledIn2Sv,
+		IsEnforcedIn2Sv:           user.IsEnforcedIn2Sv,
+		AgreedToTerms:             user.AgreedToTerms,
+		ChangePasswordAtNextLogin: user.ChangePasswordAtNextLogin,
+		HasRecoveryEmail:          user.RecoveryEmail != "",
+		HasRecoveryPhone:          user.RecoveryPhone != "",
+	}
+}
+
+// LastLogin returns the last login time of the user, false when the user never logged in.
+// The Directory API returns the 1970 epoch for the users that never logged in.
+func (receiver *GoogleUser) LastLogin() (time.Time, bool) {
+	lastLogin, err := time.Parse(time.RFC3339, receiver.LastLoginTime)
+	if err != nil || lastLogin.Year() <= 1970 {
+		return time.Time{}, false
+	}
+	return lastLogin, true
 }
 
-// GetUsersAndToken This method is used to get a list of users and their tokens
+// Created returns the creation time of the user, the zero time when it is unknown
+func (receiver *GoogleUser) Created() time.Time {
+	created, _ := time.Parse(time.RFC3339, receiver.CreationTime)
+	return created
+}
+
+// GetUsersAndToken This method is used to get a list of users and their tokens, the users collected so far are returned with a WorkerPool.IncompleteError when the context is done.
+// A user whose tokens cannot be listed is returned with the error in its TokensErr field.
 func (receiver *DirectoryAPI) GetUsersAndToken(q string) ([]*GoogleUser, error) {
 	userList, err := receiver.QueryUsers(q)
 	if err != nil {
 		log.Printf("Error getting users: %s", q)
 		return nil, err
 	}
-	var users []*GoogleUser
 
-	// Print the users to a firestore collection----------------------------------------------------------
-	totalJobs := len(userList)
-	maxExecutes := 100
-	totalBatches := (totalJobs / maxExecutes) + 1
-	batchCounter := 1
+	// Get the tokens of every user with the worker pool, the results keep the order of the users
+	results := WorkerPool.Run(receiver.Ctx, userList, WorkerPool.Options{
+		Concurrency: receiver.Concurrency,
+		Progress:    WorkerPool.LogProgress("Users Tokens", 100),
+	}, receiver.getUserAndToken)
+
+	// Return the users collected so far when the context is done
+	users := WorkerPool.Values(results)
+	return users, WorkerPool.Incomplete(receiver.Ctx, "users", results)
+}
+
+// StreamUsersAndToken This method is used to stream the users and their tokens, the tokens are listed while the users are still paginated.
+// The users are sent in the order their tokens are received, an item holding the error of a page of users ends the stream.
+// The channel must be read until it is closed, once the context is done the users not yet collected are sent with its error.
+func (receiver *DirectoryAPI) StreamUsersAndToken(q string) <-chan Item[*GoogleUser] {
+	ctx := receiver.Ctx
+	googleUsers := make(chan Item[*GoogleUser])
+
+	// Forward the users to the worker pool, keeping the error of the pagination for the end of the stream
+	users := make(chan *directory.User)
+	pageErrs := make(chan error, 1)
+	go func() {
+		defer close(users)
+		for item := range receiver.StreamUsers(q) {
+			if item.Err != nil {
+				pageErrs <- item.Err
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case users <- item.Value:
+			}
+		}
+	}()
+
+	go func() {
+		defer close(googleUsers)
+		results := WorkerPool.Stream(ctx, users, WorkerPool.Options{
+			Concurrency: receiver.Concurrency,
+			Progress:    WorkerPool.LogProgress("Users Tokens", 100),
+		}, receiver.getUserAndToken)
+		for result := range results {
+			googleUsers <- Item[*GoogleUser]{Value: result.Value, Err: result.Err}
+		}
+		// The results are closed after the users channel, the error of the pagination is already sent
+		select {
+		case pageErr := <-pageErrs:
+			googleUsers <- Item[*GoogleUser]{Err: pageErr}
+		default:
+		}
+	}()
+	return googleUsers
+}
+
+// getUserAndToken returns the GoogleUser of a user with its tokens, or with TokensErr when they cannot be listed
+func (receiver *DirectoryAPI) getUserAndToken(ctx context.Context, user *directory.User) (*GoogleUser, error) {
+	// Get the user tokens
+	tokens, err := receiver.GetUserTokens(user.PrimaryEmail)
+	// Check for errors
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	} else if err != nil {
+		log.Printf("Error getting tokens for user: %s", user.PrimaryEmail)
+	} else if tokens == nil {
+		tokens = []*directory.Token{}
+	}
+	// Pass the tokens to the user
+	googleUser := NewGoogleUser(user)
+	googleUser.Tokens = tokens
+	googleUser.TokensErr = err
+	return googleUser, nil
+}
+
+// GetRoles This method lists the system and custom admin roles of the customer
+func (receiver *DirectoryAPI) GetRoles() ([]*directory.Role, error) {
+	var roles []*directory.Role
+	pageToken := ""
 	for {
-		log.Printf("<----- Users Tokens Batch [%d] of [%d] ----->\n", batchCounter, totalBatches)
-		if len(userList) < maxExecutes {
-			maxExecutes = len(userList)
+		var page *directory.Roles
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			page, err = receiver.DirectoryService.Roles.List(receiver.Customer).Fields(receiver.Fields.Get(RolesListCall)).PageToken(pageToken).Context(receiver.Ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
 		}
+		roles = append(roles, page.Items...)
 
-		wg := &sync.WaitGroup{}
-		wg.Add(maxExecutes)
-
-		for _, job := range userList[:maxExecutes] {
-			go func(user *directory.User) {
-				defer wg.Done()
-				// Get the user tokens
-				tokens, err := receiver.GetUserTokens(user.PrimaryEmail)
-				// Check for errors
-				if err != nil {
-					fmt.Printf("Error getting tokens for user: %s", user.PrimaryEmail)
-					return
-				} else if tokens == nil {
-					log.Printf("No tokens for user: %s", user.PrimaryEmail)
-					return
-				}
-				// Serialize the tokens
-				data, _ := json.Marshal(tokens)
-				// Pass the tokens to the user
-				u := &GoogleUser{
-					Id:               user.Id,
-					PrimaryEmail:     user.PrimaryEmail,
-					Archived:         user.Archived,
-					IsAdmin:          user.IsAdmin,
-					IsDelegatedAdmin: user.IsDelegatedAdmin,
-					LastLoginTime:    user.LastLoginTime,
-					Suspended:        user.Suspended,
-					IsMailboxSetup:   user.IsMailboxSetup,
-					Tokens:           string(data),
-				}
-				users = append(users, u)
-			}(job)
+		// Check if there is a next page and add it to the page token
+		pageToken = page.NextPageToken
+		if pageToken == "" {
+			break
 		}
-		wg.Wait()
+	}
+	return roles, nil
+}
 
-		userList = userList[maxExecutes:]
-		if len(userList) == 0 {
+// GetPrivileges This method lists the privileges that can be granted by the admin roles, with their child privileges
+func (receiver *DirectoryAPI) GetPrivileges() ([]*directory.Privilege, error) {
+	var privileges *directory.Privileges
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		privileges, err = receiver.DirectoryService.Privileges.List(receiver.Customer).Fields(receiver.Fields.Get(PrivilegesListCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return privileges.Items, nil
+}
+
+// GetRoleAssignments This method lists the assignments of the admin roles, only those of the given user or group when userKey is not empty
+func (receiver *DirectoryAPI) GetRoleAssignments(userKey string) ([]*directory.RoleAssignment, error) {
+	var assignments []*directory.RoleAssignment
+	pageToken := ""
+	for {
+		request := receiver.DirectoryService.RoleAssignments.List(receiver.Customer).Fields(receiver.Fields.Get(RoleAssignmentsListCall)).PageToken(pageToken)
+		if userKey != "" {
+			request.UserKey(userKey)
+		}
+		var page *directory.RoleAssignments
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			page, err = request.Context(receiver.Ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, page.Items...)
+
+		// Check if there is a next page and add it to the page token
+		pageToken = page.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+	return assignments, nil
+}
+
+// GetOrgUnits This method lists every organizational unit of the customer below the root unit
+func (receiver *DirectoryAPI) GetOrgUnits() ([]*directory.OrgUnit, error) {
+	var orgUnits *directory.OrgUnits
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		orgUnits, err = receiver.DirectoryService.Orgunits.List(receiver.Customer).Type("all").Fields(receiver.Fields.Get(OrgUnitsListCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orgUnits.OrganizationUnits, nil
+}
+
+// GetOrgUnitTree This method builds the tree of the organizational units with the users of QueryUsers placed in their unit
+func (receiver *DirectoryAPI) GetOrgUnitTree() (*OrgUnitTree, error) {
+	orgUnits, err := receiver.GetOrgUnits()
+	if err != nil {
+		return nil, err
+	}
+	users, err := receiver.QueryUsers("")
+	if err != nil {
+		return nil, err
+	}
+	return NewOrgUnitTree(orgUnits, users), nil
+}
+
+// GetMobileDevices This method lists the mobile devices of the customer with their full projection
+func (receiver *DirectoryAPI) GetMobileDevices() ([]*directory.MobileDevice, error) {
+	var devices []*directory.MobileDevice
+	pageToken := ""
+	for {
+		var page *directory.MobileDevices
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			page, err = receiver.DirectoryService.Mobiledevices.List(receiver.Customer).Projection("FULL").Fields(receiver.Fields.Get(MobileDevicesListCall)).PageToken(pageToken).Context(receiver.Ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, page.Mobiledevices...)
+
+		// Check if there is a next page and add it to the page token
+		pageToken = page.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+	return devices, nil
+}
+
+// GetChromeOSDevices This method lists the Chrome OS devices of the customer with their full projection
+func (receiver *DirectoryAPI) GetChromeOSDevices() ([]*directory.ChromeOsDevice, error) {
+	var devices []*directory.ChromeOsDevice
+	pageToken := ""
+	for {
+		var page *directory.ChromeOsDevices
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			page, err = receiver.DirectoryService.Chromeosdevices.List(receiver.Customer).Projection("FULL").Fields(receiver.Fields.Get(ChromeOSDevicesListCall)).PageToken(pageToken).Context(receiver.Ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, page.Chromeosdevices...)
+
+		// Check if there is a next page and add it to the page token
+		pageToken = page.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+	return devices, nil
+}
+
+// MobileDeviceOwners returns the lowercase emails of the accounts synced on a mobile device
+func MobileDeviceOwners(device *directory.MobileDevice) []string {
+	var owners []string
+	for _, email := range device.Email {
+		owners = appendOwner(owners, email)
+	}
+	return owners
+}
+
+// ChromeOSDeviceOwners returns the lowercase emails of the annotated user and of the managed recent users of a Chrome OS device
+func ChromeOSDeviceOwners(device *directory.ChromeOsDevice) []string {
+	var owners []string
+	if strings.Contains(device.AnnotatedUser, "@") {
+		owners = appendOwner(owners, device.AnnotatedUser)
+	}
+	for _, user := range device.RecentUsers {
+		if user.Type == "USER_TYPE_MANAGED" {
+			owners = appendOwner(owners, user.Email)
+		}
+	}
+	return owners
+}
+
+// appendOwner appends the lowercase email to the owners when it is not empty or already listed
+func appendOwner(owners []string, email string) []string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return owners
+	}
+	for _, owner := range owners {
+		if owner == email {
+			return owners
+		}
+	}
+	return append(owners, email)
+}
+
+// GetDomains This method lists the primary and secondary domains of the customer
+func (receiver *DirectoryAPI) GetDomains() ([]*directory.Domains, error) {
+	var domains *directory.Domains2
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		domains, err = receiver.DirectoryService.Domains.List(receiver.Customer).Fields(receiver.Fields.Get(DomainsListCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domains.Domains, nil
+}
+
+// GetDomainAliases This method lists the domain aliases of every domain of the customer
+func (receiver *DirectoryAPI) GetDomainAliases() ([]*directory.DomainAlias, error) {
+	var aliases *directory.DomainAliases
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		aliases, err = receiver.DirectoryService.DomainAliases.List(receiver.Customer).Fields(receiver.Fields.Get(DomainAliasesListCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aliases.DomainAliases, nil
+}
+
+// GetVerifiedDomains This method returns the names of the verified domains and domain aliases of the customer
+func (receiver *DirectoryAPI) GetVerifiedDomains() ([]string, error) {
+	domains, err := receiver.GetDomains()
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := receiver.GetDomainAliases()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, domain := range domains {
+		if domain.Verified {
+			names = append(names, domain.DomainName)
+		}
+	}
+	for _, alias := range aliases {
+		if alias.Verified {
+			names = append(names, alias.DomainAliasName)
+		}
+	}
+	return names, nil
+}
+
+// GetBuildings This method lists the buildings of the customer
+func (receiver *DirectoryAPI) GetBuildings() ([]*directory.Building, error) {
+	var buildings []*directory.Building
+	pageToken := ""
+	for {
+		var page *directory.Buildings
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			page, err = receiver.DirectoryService.Resources.Buildings.List(receiver.Customer).Fields(receiver.Fields.Get(BuildingsListCall)).PageToken(pageToken).Context(receiver.Ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		buildings = append(buildings, page.Buildings...)
+
+		// Check if there is a next page and add it to the page token
+		pageToken = page.NextPageToken
+		if pageToken == "" {
 			break
 		}
-		batchCounter++
 	}
+	return buildings, nil
+}
+
+// GetCalendarResources This method lists the calendar resources of the customer, like the rooms and the equipment
+func (receiver *DirectoryAPI) GetCalendarResources() ([]*directory.CalendarResource, error) {
+	var resources []*directory.CalendarResource
+	pageToken := ""
+	for {
+		var page *directory.CalendarResources
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			page, err = receiver.DirectoryService.Resources.Calendars.List(receiver.Customer).Fields(receiver.Fields.Get(CalendarsListCall)).PageToken(pageToken).Context(receiver.Ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, page.Items...)
 
-	return users, nil
+		// Check if there is a next page and add it to the page token
+		pageToken = page.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+	return resources, nil
 }
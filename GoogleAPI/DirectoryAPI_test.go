@@ -0,0 +1,291 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"errors"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"google.golang.org/api/googleapi"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestQueryUsersPaginates(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	users, err := directoryAPI.QueryUsers("")
+	if err != nil {
+		t.Fatalf("QueryUsers: %v", err)
+	}
+	if len(users) != 5 {
+		t.Fatalf("QueryUsers returned %d users, want 5", len(users))
+	}
+	if got := server.Requests("/admin/directory/v1/users"); got != 3 {
+		t.Errorf("QueryUsers made %d requests, want 3 pages", got)
+	}
+}
+
+func TestQueryUsersRetriesRateLimit(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+	directoryAPI.Retry.InitialBackoff = time.Millisecond
+	server.InjectError("/admin/directory/v1/users", http.StatusForbidden, "rateLimitExceeded", 2)
+
+	users, err := directoryAPI.QueryUsers("")
+	if err != nil {
+		t.Fatalf("QueryUsers: %v", err)
+	}
+	if len(users) != 5 {
+		t.Errorf("QueryUsers returned %d users, want 5", len(users))
+	}
+}
+
+func TestQueryUsersGivesUpAfterMaxRetries(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+	directoryAPI.Retry.InitialBackoff = time.Millisecond
+	directoryAPI.Retry.MaxRetries = 2
+	server.InjectError("/admin/directory/v1/users", http.StatusServiceUnavailable, "backendError", -1)
+
+	_, err := directoryAPI.QueryUsers("")
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("QueryUsers error = %v, want a 503", err)
+	}
+	if got := server.Requests("/admin/directory/v1/users"); got != 3 {
+		t.Errorf("QueryUsers made %d requests, want 3", got)
+	}
+}
+
+func TestQueryUsersStopsRetryingWhenCancelled(t *testing.T) {
+	server := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 60, ctx)
+	server.InjectError("/admin/directory/v1/users", http.StatusServiceUnavailable, "backendError", -1)
+
+	timer := time.Now()
+	_, err := directoryAPI.QueryUsers("")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("QueryUsers error = %v, want the deadline of the context", err)
+	}
+	if elapsed := time.Since(timer); elapsed > 10*time.Second {
+		t.Errorf("QueryUsers returned after %s, want the backoff to stop with the context", elapsed)
+	}
+}
+
+func TestGetUserTokensDoesNotRetryPermissionErrors(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+	server.InjectError("/admin/directory/v1/users/alice@example.com/tokens", http.StatusForbidden, "forbidden", 1)
+
+	if _, err := directoryAPI.GetUserTokens("alice@example.com"); err == nil {
+		t.Fatal("GetUserTokens succeeded, want the injected 403")
+	}
+	if got := server.Requests("/admin/directory/v1/users/alice@example.com/tokens"); got != 1 {
+		t.Errorf("GetUserTokens made %d requests, want 1", got)
+	}
+}
+
+func TestQueryGroupsAndMembers(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	groups, err := directoryAPI.QueryGroups("")
+	if err != nil {
+		t.Fatalf("QueryGroups: %v", err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("QueryGroups returned %d groups, want 3", len(groups))
+	}
+
+	members, err := directoryAPI.GetGroupMembers(groups[0], "")
+	if err != nil {
+		t.Fatalf("GetGroupMembers: %v", err)
+	}
+	if len(members) != 3 {
+		t.Errorf("GetGroupMembers returned %d members, want 3", len(members))
+	}
+
+	owners, err := directoryAPI.GetGroupMembers(groups[0], "OWNER")
+	if err != nil {
+		t.Fatalf("GetGroupMembers: %v", err)
+	}
+	if len(owners) != 1 || owners[0].Email != "alice@example.com" {
+		t.Errorf("GetGroupMembers(OWNER) = %v, want alice@example.com", owners)
+	}
+
+	parents, err := directoryAPI.GetSubscriptions("admins@example.com")
+	if err != nil {
+		t.Fatalf("GetSubscriptions: %v", err)
+	}
+	if len(parents) != 1 || parents[0].Email != "all@example.com" {
+		t.Errorf("GetSubscriptions = %v, want all@example.com", parents)
+	}
+}
+
+func TestGetUserASPsAndVerificationCodes(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	asps, err := directoryAPI.GetUserASPs("alice@example.com")
+	if err != nil {
+		t.Fatalf("GetUserASPs: %v", err)
+	}
+	if len(asps) != 1 || asps[0].Name != "Thunderbird" || asps[0].LastTimeUsed != 1685620800000 {
+		t.Errorf("GetUserASPs = %v, want the Thunderbird password", asps)
+	}
+	codes, err := directoryAPI.GetUserVerificationCodes("admin@example.com")
+	if err != nil {
+		t.Fatalf("GetUserVerificationCodes: %v", err)
+	}
+	if len(codes) != 2 {
+		t.Errorf("GetUserVerificationCodes returned %d codes, want 2", len(codes))
+	}
+	// The codes are never requested, even with every field
+	directoryAPI.Fields = GoogleAPI.FieldMasks{All: true}
+	codes, err = directoryAPI.GetUserVerificationCodes("admin@example.com")
+	if err != nil || len(codes) != 2 || codes[0].UserId != "1" || codes[0].VerificationCode != "" || codes[1].VerificationCode != "" {
+		t.Errorf("GetUserVerificationCodes = %+v, %v, want the 2 codes without their value", codes, err)
+	}
+	directoryAPI.Fields = GoogleAPI.FieldMasks{}
+	if codes, err := directoryAPI.GetUserVerificationCodes("alice@example.com"); err != nil || len(codes) != 0 {
+		t.Errorf("GetUserVerificationCodes(alice@example.com) = %v, %v, want no codes", codes, err)
+	}
+}
+
+func TestGetUsersAndToken(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	users, err := directoryAPI.GetUsersAndToken("")
+	if err != nil {
+		t.Fatalf("GetUsersAndToken: %v", err)
+	}
+	for _, user := range users {
+		if user.PrimaryEmail == "alice@example.com" {
+			if len(user.Tokens) != 1 || user.Tokens[0].ClientId != "app-1" {
+				t.Errorf("GetUsersAndToken tokens of %s = %v, want app-1", user.PrimaryEmail, user.Tokens)
+			}
+			if user.OrgUnitPath != "/Engineering" || !user.IsEnrolledIn2Sv || user.IsEnforcedIn2Sv || !user.HasRecoveryPhone || user.HasRecoveryEmail {
+				t.Errorf("GetUsersAndToken user = %+v, want the 2SV and recovery fields of alice@example.com", user)
+			}
+			return
+		}
+	}
+	t.Error("GetUsersAndToken did not return alice@example.com")
+}
+
+func TestGoogleUserLastLogin(t *testing.T) {
+	tests := []struct {
+		lastLoginTime string
+		loggedIn      bool
+	}{
+		{"2023-05-01T12:00:00.000Z", true},
+		{"1970-01-01T00:00:00.000Z", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		user := &GoogleAPI.GoogleUser{LastLoginTime: test.lastLoginTime}
+		if lastLogin, loggedIn := user.LastLogin(); loggedIn != test.loggedIn || loggedIn && lastLogin.Month() != 5 {
+			t.Errorf("LastLogin(%q) = %s, %v, want logged in %v", test.lastLoginTime, lastLogin, loggedIn, test.loggedIn)
+		}
+	}
+}
+
+func TestGetRolesAndAssignments(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	roles, err := directoryAPI.GetRoles()
+	if err != nil {
+		t.Fatalf("GetRoles: %v", err)
+	}
+	if len(roles) != 2 || !roles[0].IsSuperAdminRole || roles[1].RolePrivileges[0].PrivilegeName != "USERS_RESET_PASSWORD" {
+		t.Errorf("GetRoles = %v, want the super admin and helpdesk roles", roles)
+	}
+
+	privileges, err := directoryAPI.GetPrivileges()
+	if err != nil {
+		t.Fatalf("GetPrivileges: %v", err)
+	}
+	if len(privileges) != 1 || len(privileges[0].ChildPrivileges) != 1 {
+		t.Errorf("GetPrivileges = %v, want USERS_ALL and its child", privileges)
+	}
+
+	// The assignments are paginated by the fake server
+	assignments, err := directoryAPI.GetRoleAssignments("")
+	if err != nil {
+		t.Fatalf("GetRoleAssignments: %v", err)
+	}
+	if len(assignments) != 3 {
+		t.Errorf("GetRoleAssignments returned %d assignments, want 3", len(assignments))
+	}
+	assignments, err = directoryAPI.GetRoleAssignments("admin@example.com")
+	if err != nil {
+		t.Fatalf("GetRoleAssignments: %v", err)
+	}
+	if len(assignments) != 1 || assignments[0].RoleId != 10 {
+		t.Errorf("GetRoleAssignments(admin@example.com) = %v, want the super admin role", assignments)
+	}
+}
+
+func TestGetDevices(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	// The mobile devices are paginated by the fake server
+	mobileDevices, err := directoryAPI.GetMobileDevices()
+	if err != nil {
+		t.Fatalf("GetMobileDevices: %v", err)
+	}
+	if len(mobileDevices) != 3 {
+		t.Fatalf("GetMobileDevices returned %d devices, want 3", len(mobileDevices))
+	}
+	if owners := GoogleAPI.MobileDeviceOwners(mobileDevices[0]); len(owners) != 1 || owners[0] != "alice@example.com" {
+		t.Errorf("MobileDeviceOwners = %v, want alice@example.com", owners)
+	}
+
+	chromeOSDevices, err := directoryAPI.GetChromeOSDevices()
+	if err != nil {
+		t.Fatalf("GetChromeOSDevices: %v", err)
+	}
+	if len(chromeOSDevices) != 1 {
+		t.Fatalf("GetChromeOSDevices returned %d devices, want 1", len(chromeOSDevices))
+	}
+	// The annotated user and the managed recent user are the same, the unmanaged recent user is not an owner
+	if owners := GoogleAPI.ChromeOSDeviceOwners(chromeOSDevices[0]); len(owners) != 1 || owners[0] != "alice@example.com" {
+		t.Errorf("ChromeOSDeviceOwners = %v, want alice@example.com", owners)
+	}
+}
+
+func TestGetDomainsAndResources(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	// The unverified domain is left out, the verified alias is kept
+	domains, err := directoryAPI.GetVerifiedDomains()
+	if err != nil {
+		t.Fatalf("GetVerifiedDomains: %v", err)
+	}
+	if len(domains) != 2 || domains[0] != "example.com" || domains[1] != "example.org" {
+		t.Errorf("GetVerifiedDomains = %v, want example.com and example.org", domains)
+	}
+
+	// The buildings are paginated by the fake server
+	buildings, err := directoryAPI.GetBuildings()
+	if err != nil {
+		t.Fatalf("GetBuildings: %v", err)
+	}
+	if len(buildings) != 3 {
+		t.Errorf("GetBuildings returned %d buildings, want 3", len(buildings))
+	}
+	resources, err := directoryAPI.GetCalendarResources()
+	if err != nil {
+		t.Fatalf("GetCalendarResources: %v", err)
+	}
+	if len(resources) != 1 || resources[0].BuildingId != "hq" || resources[0].Capacity != 12 {
+		t.Errorf("GetCalendarResources = %v, want the board room", resources)
+	}
+}
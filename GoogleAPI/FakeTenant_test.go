@@ -0,0 +1,121 @@
+package GoogleAPI_test
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/FakeGoogleAPI"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/iam/v1"
+	"testing"
+)
+
+// testTenant returns a small tenant with a user, group, drive and project of every kind used by the tests
+func testTenant() *FakeGoogleAPI.Tenant {
+	return &FakeGoogleAPI.Tenant{
+		Users: []*directory.User{
+			{Id: "1", PrimaryEmail: "admin@example.com", IsAdmin: true, LastLoginTime: "2023-06-01T12:00:00.000Z"},
+			{Id: "2", PrimaryEmail: "alice@example.com", LastLoginTime: "2023-05-01T12:00:00.000Z", OrgUnitPath: "/Engineering", IsEnrolledIn2Sv: true, RecoveryPhone: "+15555550100"},
+			{Id: "3", PrimaryEmail: "bob@example.com", Suspended: true, LastLoginTime: "1970-01-01T00:00:00.000Z"},
+			{Id: "4", PrimaryEmail: "carol@example.com", IsDelegatedAdmin: true},
+			{Id: "5", PrimaryEmail: "dave@example.com", Archived: true},
+		},
+		Groups: []*directory.Group{
+			{Id: "g1", Email: "all@example.com", Name: "All", DirectMembersCount: 3},
+			{Id: "g2", Email: "admins@example.com", Name: "Admins", DirectMembersCount: 2, AdminCreated: true},
+			{Id: "g3", Email: "empty@example.com", Name: "Empty"},
+		},
+		Members: map[string][]*directory.Member{
+			"all@example.com": {
+				{Email: "alice@example.com", Role: "OWNER", Type: "USER"},
+				{Email: "bob@example.com", Role: "MANAGER", Type: "USER"},
+				{Email: "admins@example.com", Role: "MEMBER", Type: "GROUP"},
+			},
+			"admins@example.com": {
+				{Email: "admin@example.com", Role: "OWNER", Type: "USER"},
+				{Email: "partner@other.org", Role: "MEMBER", Type: "USER"},
+			},
+		},
+		Roles: []*directory.Role{
+			{RoleId: 10, RoleName: "_SEED_ADMIN_ROLE", IsSystemRole: true, IsSuperAdminRole: true},
+			{RoleId: 11, RoleName: "Helpdesk", RolePrivileges: []*directory.RoleRolePrivileges{{PrivilegeName: "USERS_RESET_PASSWORD", ServiceId: "00haapch16h1ysv"}}},
+		},
+		Privileges: []*directory.Privilege{
+			{PrivilegeName: "USERS_ALL", ServiceId: "00haapch16h1ysv", ServiceName: "admin_apis", ChildPrivileges: []*directory.Privilege{{PrivilegeName: "USERS_RESET_PASSWORD", ServiceId: "00haapch16h1ysv"}}},
+		},
+		RoleAssignments: []*directory.RoleAssignment{
+			{RoleAssignmentId: 100, RoleId: 10, AssignedTo: "1", AssigneeType: "user", ScopeType: "CUSTOMER"},
+			{RoleAssignmentId: 101, RoleId: 11, AssignedTo: "g2", AssigneeType: "group", ScopeType: "ORG_UNIT", OrgUnitId: "ou1"},
+			{RoleAssignmentId: 102, RoleId: 10, AssignedTo: "sa-unique-id", AssigneeType: "user", ScopeType: "CUSTOMER"},
+		},
+		MobileDevices: []*directory.MobileDevice{
+			{ResourceId: "m1", Email: []string{"Alice@example.com"}, Model: "Pixel 7", Os: "Android 14", Type: "ANDROID", DeviceCompromisedStatus: "No compromise detected", EncryptionStatus: "Encrypted"},
+			{ResourceId: "m2", Email: []string{"bob@example.com"}, Model: "iPhone", Os: "iOS 16.5", Type: "IOS_SYNC", DeviceCompromisedStatus: "Compromise detected", EncryptionStatus: "Unencrypted"},
+			{ResourceId: "m3", Email: []string{"partner@other.org"}, Model: "Galaxy", Os: "Android 12", Type: "GOOGLE_SYNC"},
+		},
+		ChromeOSDevices: []*directory.ChromeOsDevice{
+			{DeviceId: "c1", AnnotatedUser: "alice@example.com", Model: "Chromebook", OsVersion: "120.0", OrgUnitPath: "/Engineering", Status: "ACTIVE",
+				RecentUsers: []*directory.ChromeOsDeviceRecentUsers{{Email: "alice@example.com", Type: "USER_TYPE_MANAGED"}, {Email: "guest@gmail.com", Type: "USER_TYPE_UNMANAGED"}}},
+		},
+		Domains: []*directory.Domains{
+			{DomainName: "example.com", IsPrimary: true, Verified: true},
+			{DomainName: "example.net", Verified: false},
+		},
+		DomainAliases: []*directory.DomainAlias{{DomainAliasName: "example.org", ParentDomainName: "example.com", Verified: true}},
+		Buildings: []*directory.Building{
+			{BuildingId: "hq", BuildingName: "Headquarters", FloorNames: []string{"1", "2"}},
+			{BuildingId: "lab", BuildingName: "Lab"},
+			{BuildingId: "annex", BuildingName: "Annex"},
+		},
+		CalendarResources: []*directory.CalendarResource{
+			{ResourceId: "r1", ResourceName: "Board Room", ResourceEmail: "c_board@resource.calendar.google.com", ResourceCategory: "CONFERENCE_ROOM", BuildingId: "hq", FloorName: "2", Capacity: 12},
+		},
+		OrgUnits: []*directory.OrgUnit{
+			{OrgUnitId: "id:ou1", Name: "Engineering", OrgUnitPath: "/Engineering", ParentOrgUnitId: "id:root", ParentOrgUnitPath: "/"},
+			{OrgUnitId: "id:ou2", Name: "Platform", OrgUnitPath: "/Engineering/Platform", ParentOrgUnitId: "id:ou1", ParentOrgUnitPath: "/Engineering", BlockInheritance: true},
+		},
+		GroupSettings: map[string]*groupssettings.Groups{
+			"all@example.com":    {Email: "all@example.com", WhoCanJoin: "ALL_IN_DOMAIN_CAN_JOIN", WhoCanPostMessage: "ALL_IN_DOMAIN_CAN_POST", AllowExternalMembers: "false"},
+			"admins@example.com": {Email: "admins@example.com", WhoCanJoin: "ANYONE_CAN_JOIN", WhoCanPostMessage: "ANYONE_CAN_POST", AllowExternalMembers: "true"},
+		},
+		Tokens: map[string][]*directory.Token{
+			"alice@example.com": {{ClientId: "app-1", DisplayText: "App One", Scopes: []string{drive.DriveScope}}},
+		},
+		ASPs: map[string][]*directory.Asp{
+			"alice@example.com": {{CodeId: 1, Name: "Thunderbird", CreationTime: 1672531200000, LastTimeUsed: 1685620800000}},
+		},
+		VerificationCodes: map[string][]*directory.VerificationCode{
+			"admin@example.com": {{UserId: "1", VerificationCode: "12345678"}, {UserId: "1", VerificationCode: "87654321"}},
+		},
+		Drives: []*drive.Drive{{Id: "d1", Name: "Finance"}, {Id: "d2", Name: "Legal"}, {Id: "d3", Name: "Public"}},
+		Files: []*drive.File{
+			{Id: "f1", Name: "Script", MimeType: "application/vnd.google-apps.script", Owners: []*drive.User{{EmailAddress: "alice@example.com"}}},
+			{Id: "f2", Name: "Doc", MimeType: "application/vnd.google-apps.document", Owners: []*drive.User{{EmailAddress: "alice@example.com"}}},
+			{Id: "f3", Name: "Other Script", MimeType: "application/vnd.google-apps.script", Owners: []*drive.User{{EmailAddress: "bob@example.com"}}},
+		},
+		Permissions: map[string][]*drive.Permission{
+			"d1": {
+				{Id: "p1", Type: "user", Role: "organizer", EmailAddress: "alice@example.com"},
+				{Id: "p2", Type: "group", Role: "writer", EmailAddress: "all@example.com"},
+				{Id: "p3", Type: "domain", Role: "reader", Domain: "example.com"},
+			},
+			"d2": {{Id: "p4", Type: "user", Role: "organizer", EmailAddress: "bob@example.com"}},
+			"d3": {},
+		},
+		Projects: []*cloudresourcemanager.Project{
+			{ProjectId: "project-a", ProjectNumber: 101, Name: "Project A"},
+			{ProjectId: "project-b", ProjectNumber: 102, Name: "Project B"},
+		},
+		ServiceAccounts: map[string][]*iam.ServiceAccount{
+			"project-a": {{Email: "sa@project-a.iam.gserviceaccount.com", ProjectId: "project-a", UniqueId: "123"}},
+		},
+	}
+}
+
+// newTestServer starts a fake server with the test tenant and a small page size to exercise the pagination
+func newTestServer(t *testing.T) *FakeGoogleAPI.Server {
+	server := FakeGoogleAPI.NewServer(testTenant())
+	server.PageSize = 2
+	t.Cleanup(server.Close)
+	return server
+}
@@ -0,0 +1,103 @@
+package GoogleAPI
+
+import (
+	"google.golang.org/api/googleapi"
+	"sort"
+)
+
+// AllFields This is the field mask returning every field of a response, it is only sent when the masks opt in
+const AllFields googleapi.Field = "*"
+
+// VerificationCodesFields This is the mask of the verification codes listing, it leaves out the codes and is not overridden by FieldMasks
+const VerificationCodesFields googleapi.Field = "items(userId)"
+
+// Names of the calls sending a field mask, they are the keys of FieldMasks.Calls
+const (
+	UsersListCall           = "users.list"
+	GroupsListCall          = "groups.list"
+	MembersListCall         = "members.list"
+	TokensListCall          = "tokens.list"
+	DrivesListCall          = "drives.list"
+	FilesListCall           = "files.list"
+	FilesCreateCall         = "files.create"
+	PermissionsListCall     = "permissions.list"
+	ProjectsListCall        = "projects.list"
+	ServiceAccountsListCall = "serviceAccounts.list"
+	ServiceAccountsGetCall  = "serviceAccounts.get"
+	AspsListCall            = "asps.list"
+	RolesListCall           = "roles.list"
+	PrivilegesListCall      = "privileges.list"
+	RoleAssignmentsListCall = "roleAssignments.list"
+	OrgUnitsListCall        = "orgunits.list"
+	MobileDevicesListCall   = "mobiledevices.list"
+	ChromeOSDevicesListCall = "chromeosdevices.list"
+	DomainsListCall         = "domains.list"
+	DomainAliasesListCall   = "domainAliases.list"
+	BuildingsListCall       = "resources.buildings.list"
+	CalendarsListCall       = "resources.calendars.list"
+	GroupSettingsGetCall    = "groupsSettings.get"
+)
+
+// DefaultFieldMasks This is the minimal field mask of every call, it holds the fields read by the audits and the next page token of the listings
+var DefaultFieldMasks = map[string]googleapi.Field{
+	UsersListCall: "nextPageToken,users(id,primaryEmail,orgUnitPath,isAdmin,isDelegatedAdmin,suspended,archived,isMailboxSetup," +
+		"creationTime,lastLoginTime,isEnrolledIn2Sv,isEnforcedIn2Sv,agreedToTerms,changePasswordAtNextLogin,recoveryEmail,recoveryPhone)",
+	GroupsListCall:          "nextPageToken,groups(id,email,name,directMembersCount,adminCreated)",
+	MembersListCall:         "nextPageToken,members(id,email,role,type,status)",
+	TokensListCall:          "items(clientId,displayText,scopes,anonymous,nativeApp,userKey)",
+	DrivesListCall:          "nextPageToken,drives(id,name)",
+	FilesListCall:           "nextPageToken,files(id,name,mimeType,createdTime,viewedByMeTime,shared,teamDriveId,driveId)",
+	FilesCreateCall:         "id,name,webViewLink,size",
+	PermissionsListCall:     "nextPageToken,permissions(id,type,role,emailAddress,domain,deleted)",
+	ProjectsListCall:        "nextPageToken,projects(projectId,projectNumber,name,lifecycleState)",
+	ServiceAccountsListCall: "nextPageToken,accounts(email,oauth2ClientId,projectId,uniqueId)",
+	ServiceAccountsGetCall:  "email,oauth2ClientId,projectId,uniqueId",
+	AspsListCall:            "items(codeId,name,creationTime,lastTimeUsed)",
+	RolesListCall:           "nextPageToken,items(roleId,roleName,roleDescription,isSystemRole,isSuperAdminRole,rolePrivileges(privilegeName,serviceId))",
+	PrivilegesListCall:      "items(privilegeName,serviceId,serviceName,childPrivileges)",
+	RoleAssignmentsListCall: "nextPageToken,items(roleAssignmentId,roleId,assignedTo,assigneeType,scopeType,orgUnitId)",
+	OrgUnitsListCall:        "organizationUnits(orgUnitId,orgUnitPath,name,description,blockInheritance,parentOrgUnitId,parentOrgUnitPath)",
+	MobileDevicesListCall: "nextPageToken,mobiledevices(resourceId,serialNumber,email,type,status,manufacturer,model,os,firstSync,lastSync," +
+		"deviceCompromisedStatus,encryptionStatus,devicePasswordStatus)",
+	ChromeOSDevicesListCall: "nextPageToken,chromeosdevices(deviceId,serialNumber,annotatedUser,recentUsers(email,type),orgUnitPath,status,model," +
+		"osVersion,firstEnrollmentTime,lastSync)",
+	DomainsListCall:       "domains(domainName,isPrimary,verified,creationTime)",
+	DomainAliasesListCall: "domainAliases(domainAliasName,parentDomainName,verified,creationTime)",
+	BuildingsListCall: "nextPageToken,buildings(buildingId,buildingName,description," +
+		"address(addressLines,locality,administrativeArea,postalCode,regionCode),floorNames)",
+	CalendarsListCall: "nextPageToken,items(resourceId,resourceName,resourceEmail,resourceCategory,resourceType,buildingId,floorName,capacity)",
+	GroupSettingsGetCall: "email,whoCanJoin,whoCanPostMessage,whoCanViewMembership,whoCanViewGroup,whoCanDiscoverGroup,whoCanContactOwner," +
+		"allowExternalMembers,allowWebPosting,isArchived,archiveOnly,messageModerationLevel,includeInGlobalAddressList",
+}
+
+// FieldMasks This is the field masks sent by the calls of a wrapper, the zero value sends the DefaultFieldMasks
+type FieldMasks struct {
+	// All sends AllFields on every call, for example to debug a report missing a field
+	All bool
+	// Calls overrides the mask of the calls by call name, like TokensListCall, AllFields is accepted
+	Calls map[string]googleapi.Field
+}
+
+// Get returns the mask of the call: AllFields when All is set, else its override, else its default mask
+func (receiver FieldMasks) Get(call string) googleapi.Field {
+	if receiver.All {
+		return AllFields
+	}
+	if mask, ok := receiver.Calls[call]; ok && mask != "" {
+		return mask
+	}
+	if mask, ok := DefaultFieldMasks[call]; ok {
+		return mask
+	}
+	return AllFields
+}
+
+// FieldCalls returns the sorted names of the calls sending a field mask
+func FieldCalls() []string {
+	var calls []string
+	for call := range DefaultFieldMasks {
+		calls = append(calls, call)
+	}
+	sort.Strings(calls)
+	return calls
+}
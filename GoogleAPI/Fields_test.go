@@ -0,0 +1,153 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/FakeGoogleAPI"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/groupssettings/v1"
+	"testing"
+)
+
+// syntheticSize This is the tenant of the field masks tests, the benchmarks use a larger one
+var syntheticSize = FakeGoogleAPI.SyntheticSize{Users: 50, TokensPerUser: 3, Groups: 10, MembersPerGroup: 5, Files: 20, PermissionsPerFile: 5, Drives: 5}
+
+func TestFieldMasksKeepTheFieldsOfTheAudits(t *testing.T) {
+	server := FakeGoogleAPI.NewServer(FakeGoogleAPI.NewSyntheticTenant(syntheticSize))
+	defer server.Close()
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	users, err := directoryAPI.GetUsersAndToken("")
+	if err != nil {
+		t.Fatalf("GetUsersAndToken: %v", err)
+	}
+	if len(users) != 50 || users[1].PrimaryEmail == "" || users[1].OrgUnitPath == "" || users[1].CreationTime == "" || !users[1].HasRecoveryEmail {
+		t.Fatalf("GetUsersAndToken = %d users, first %+v", len(users), users[1])
+	}
+	if token := users[1].Tokens[0]; len(users[1].Tokens) != 3 || token.ClientId == "" || len(token.Scopes) == 0 || token.Etag != "" {
+		t.Errorf("tokens = %+v, want the client, the scopes and no etag", users[1].Tokens)
+	}
+	members, err := directoryAPI.GetGroupMembers(server.Tenant().Groups[0], "")
+	if err != nil {
+		t.Fatalf("GetGroupMembers: %v", err)
+	}
+	if len(members) != 5 || members[0].Email == "" || members[0].Role != "OWNER" || members[0].Type != "USER" || members[0].Kind != "" {
+		t.Errorf("GetGroupMembers = %+v", members[0])
+	}
+	maskedBytes := server.Bytes("/admin/directory/v1/users")
+
+	// Every field is returned when the masks opt in
+	directoryAPI.Fields = GoogleAPI.FieldMasks{All: true}
+	if _, err := directoryAPI.GetUsersAndToken(""); err != nil {
+		t.Fatalf("GetUsersAndToken: %v", err)
+	}
+	allBytes := server.Bytes("/admin/directory/v1/users") - maskedBytes
+	if maskedBytes >= allBytes {
+		t.Errorf("the masked responses are %d bytes and the full responses %d bytes, want less", maskedBytes, allBytes)
+	}
+
+	// A call override replaces its default mask
+	directoryAPI.Fields = GoogleAPI.FieldMasks{Calls: map[string]googleapi.Field{GoogleAPI.UsersListCall: "nextPageToken,users/primaryEmail"}}
+	page, _, err := directoryAPI.QueryUsersPage("", "")
+	if err != nil {
+		t.Fatalf("QueryUsersPage: %v", err)
+	}
+	if page[0].PrimaryEmail == "" || page[0].Id != "" {
+		t.Errorf("QueryUsersPage = %+v, want only the primary email", page[0])
+	}
+}
+
+func TestFieldMasksOfTheDriveCalls(t *testing.T) {
+	server := FakeGoogleAPI.NewServer(FakeGoogleAPI.NewSyntheticTenant(syntheticSize))
+	defer server.Close()
+	driveAPI := GoogleAPI.NewDriveAPI(server.Client(), 0, context.Background())
+
+	drives, err := driveAPI.GetAllDrives()
+	if err != nil {
+		t.Fatalf("GetAllDrives: %v", err)
+	}
+	if len(drives) != 5 || drives[0].MetaData.Name == "" || drives[0].MetaData.Capabilities != nil {
+		t.Errorf("GetAllDrives = %+v, want the name and no capabilities", drives[0].MetaData)
+	}
+	if permission := drives[0].Permissions[0]; permission.Role == "" || permission.EmailAddress == "" || permission.DisplayName != "" {
+		t.Errorf("permission = %+v, want the role, the email and no display name", permission)
+	}
+}
+
+func TestFieldMasksOfTheAdminCalls(t *testing.T) {
+	server := FakeGoogleAPI.NewServer(&FakeGoogleAPI.Tenant{
+		Groups:        []*directory.Group{{Id: "g1", Email: "all@example.com"}},
+		Roles:         []*directory.Role{{RoleId: 10, RoleName: "Helpdesk", Etag: "etag"}},
+		Domains:       []*directory.Domains{{DomainName: "example.com", Verified: true, Etag: "etag"}},
+		MobileDevices: []*directory.MobileDevice{{ResourceId: "m1", Email: []string{"alice@example.com"}, Imei: "123456789012345"}},
+		GroupSettings: map[string]*groupssettings.Groups{"all@example.com": {Email: "all@example.com", WhoCanJoin: "ALL_IN_DOMAIN_CAN_JOIN", Description: "Everyone"}},
+	})
+	defer server.Close()
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+	groupsSettingsAPI := GoogleAPI.NewGroupsSettingsAPI(server.Client(), 0, context.Background())
+
+	for _, fields := range []GoogleAPI.FieldMasks{{}, {All: true}} {
+		directoryAPI.Fields, groupsSettingsAPI.Fields = fields, fields
+		roles, err := directoryAPI.GetRoles()
+		if err != nil {
+			t.Fatalf("GetRoles: %v", err)
+		}
+		domains, err := directoryAPI.GetDomains()
+		if err != nil {
+			t.Fatalf("GetDomains: %v", err)
+		}
+		devices, err := directoryAPI.GetMobileDevices()
+		if err != nil {
+			t.Fatalf("GetMobileDevices: %v", err)
+		}
+		settings, err := groupsSettingsAPI.GetGroupSettings("all@example.com")
+		if err != nil {
+			t.Fatalf("GetGroupSettings: %v", err)
+		}
+		if roles[0].RoleName != "Helpdesk" || domains[0].DomainName != "example.com" || devices[0].Email[0] != "alice@example.com" || settings.WhoCanJoin == "" {
+			t.Errorf("fields %+v dropped a field read by the audits", fields)
+		}
+		// Only the fields read by the audits are returned unless every field is requested
+		unread := []bool{roles[0].Etag != "", domains[0].Etag != "", devices[0].Imei != "", settings.Description != ""}
+		for i, returned := range unread {
+			if returned != fields.All {
+				t.Errorf("fields %+v: unread field %d returned = %v", fields, i, returned)
+			}
+		}
+	}
+}
+
+// BenchmarkFieldMasks lists the users with their tokens and the shared drives with their permissions of a large synthetic tenant,
+// with the minimal masks and with every field, and reports the bytes received per run.
+// Every response is sent at 1 MB/s to account for the time spent on the network.
+func BenchmarkFieldMasks(b *testing.B) {
+	tenant := FakeGoogleAPI.NewSyntheticTenant(FakeGoogleAPI.SyntheticSize{Users: 2000, TokensPerUser: 3, Drives: 500, PermissionsPerFile: 10})
+	for _, benchmark := range []struct {
+		name   string
+		fields GoogleAPI.FieldMasks
+	}{
+		{"minimal", GoogleAPI.FieldMasks{}},
+		{"all", GoogleAPI.FieldMasks{All: true}},
+	} {
+		b.Run(benchmark.name, func(b *testing.B) {
+			server := FakeGoogleAPI.NewServer(tenant)
+			server.Bandwidth = 1 << 20
+			defer server.Close()
+			directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+			directoryAPI.Fields = benchmark.fields
+			driveAPI := GoogleAPI.NewDriveAPI(server.Client(), 0, context.Background())
+			driveAPI.Fields = benchmark.fields
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := directoryAPI.GetUsersAndToken(""); err != nil {
+					b.Fatalf("GetUsersAndToken: %v", err)
+				}
+				if _, err := driveAPI.GetAllDrives(); err != nil {
+					b.Fatalf("GetAllDrives: %v", err)
+				}
+			}
+			b.ReportMetric(float64(server.Bytes("/"))/float64(b.N), "response-bytes/op")
+		})
+	}
+}
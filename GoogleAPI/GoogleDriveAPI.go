@@ -4,23 +4,29 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
+	"io"
 	"log"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 )
 
 // DriveAPI This is the struct that is used to interact with the Google Drive API
 type DriveAPI struct {
-	Service   *drive.Service
-	Subject   string
-	SleepTime int
-	Jobs      *sync.WaitGroup
-	MaxTries  int
+	Service *drive.Service
+	Subject string
+	Jobs    *sync.WaitGroup
+	Retry   *RetryPolicy
+	// Concurrency is the number of files or drives processed at the same time
+	Concurrency int
+	// Ctx is the context of every request, cancelling it stops the pagination, the workers and the retries
+	Ctx context.Context
+	// Fields are the field masks of the calls, the zero value sends the minimal masks
+	Fields FieldMasks
 }
 
 // NewDriveAPI This method is used to create a new DriveAPI client
@@ -34,8 +40,9 @@ func NewDriveAPI(client *http.Client, sleepTime int, ctx context.Context) *Drive
 
 	newDriveAPI.Service = service
 	newDriveAPI.Jobs = &sync.WaitGroup{}
-	newDriveAPI.SleepTime = sleepTime
-	newDriveAPI.MaxTries = 10
+	newDriveAPI.Retry = NewRetryPolicy(time.Duration(sleepTime) * time.Second)
+	newDriveAPI.Concurrency = 10
+	newDriveAPI.Ctx = ctx
 	return newDriveAPI
 }
 
@@ -71,81 +78,63 @@ func (receiver *DriveAPI) UploadFile(fileData []byte, fileName, parentFolderId s
 			"["+byteCount(now), "of", byteCount(reader.Size())+"]")
 	})
 
-	// Try to upload the file
-	tryCounter := 0
-	// Loop until we get a result
-	for {
-		// Upload the file
-		result, err := receiver.Service.Files.Create(metaData).Media(reader).ProgressUpdater(progressUpdater).Fields("*").Do()
-		if err != nil {
-			if strings.Contains(err.Error(), "quota") && tryCounter < receiver.MaxTries {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), time.Duration(receiver.SleepTime))
-				time.Sleep(time.Duration(receiver.SleepTime) * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") && tryCounter < receiver.MaxTries {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				tryCounter++
-				continue
-			} else {
-				return nil, err
-			}
+	// Upload the file, rewinding the reader before every attempt
+	var result *drive.File
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		if _, err = reader.Seek(0, io.SeekStart); err != nil {
+			return err
 		}
-		return result, err
+		result, err = receiver.Service.Files.Create(metaData).Media(reader).ProgressUpdater(progressUpdater).Fields(receiver.Fields.Get(FilesCreateCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-
+	return result, nil
 }
 
-// GetAllDrives This method is used to get all of the shared drives
-func (receiver *DriveAPI) GetAllDrives() []*SharedDrive {
-	drivesListCall := receiver.Service.Drives.List().PageSize(100).UseDomainAdminAccess(true).Fields("*")
+// GetAllDrives This method is used to get all of the shared drives, the drives collected so far are returned with the error.
+// A drive whose permissions cannot be listed is returned with the error in its Err field.
+func (receiver *DriveAPI) GetAllDrives() ([]*SharedDrive, error) {
+	drivesListCall := receiver.Service.Drives.List().PageSize(100).UseDomainAdminAccess(true).Fields(receiver.Fields.Get(DrivesListCall))
 	var sharedDrives []*SharedDrive
 	for {
-		drivesList, err := drivesListCall.Do()
+		var drivesList *drive.DriveList
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			drivesList, err = drivesListCall.Context(receiver.Ctx).Do()
+			return err
+		})
 		if err != nil {
-			log.Println(err.Error())
-			panic(err)
+			return sharedDrives, err
 		}
 
-		totalJobs := len(drivesList.Drives)
-		maxExecutes := 10
-		totalBatches := (totalJobs / maxExecutes)
-		batchCounter := 1
-		for {
-			log.Printf("<----- Batch [%d] of [%d] ----->\n", batchCounter, totalBatches)
-			if len(drivesList.Drives) < maxExecutes {
-				maxExecutes = len(drivesList.Drives)
-			}
-
-			wg := &sync.WaitGroup{}
-			wg.Add(maxExecutes)
-
-			for _, job := range drivesList.Drives[:maxExecutes] {
-				go func(worker *drive.Drive) {
-					defer wg.Done()
-					sd := &SharedDrive{
-						MetaData:    worker,
-						Permissions: receiver.GetFilePermissions(worker.Id)}
-					for _, permission := range sd.Permissions {
-						switch permission.Type {
-						case "user":
-							sd.Users = append(sd.Users, permission)
-						case "group":
-							sd.Groups = append(sd.Groups, permission)
-						case "domain":
-							sd.Domain = permission.Domain
-						}
-					}
-					sharedDrives = append(sharedDrives, sd)
-				}(job)
+		// Get the permissions of the drives of the page with the worker pool
+		results := WorkerPool.Run(receiver.Ctx, drivesList.Drives, WorkerPool.Options{
+			Concurrency: receiver.Concurrency,
+		}, func(ctx context.Context, worker *drive.Drive) (*SharedDrive, error) {
+			permissions, err := receiver.GetFilePermissions(worker.Id)
+			if err != nil && ctx.Err() != nil {
+				return nil, err
 			}
-			wg.Wait()
-
-			drivesList.Drives = drivesList.Drives[maxExecutes:]
-			if len(drivesList.Drives) == 0 {
-				break
+			sd := &SharedDrive{
+				MetaData:    worker,
+				Permissions: permissions,
+				Err:         err}
+			for _, permission := range sd.Permissions {
+				switch permission.Type {
+				case "user":
+					sd.Users = append(sd.Users, permission)
+				case "group":
+					sd.Groups = append(sd.Groups, permission)
+				case "domain":
+					sd.Domain = permission.Domain
+				}
 			}
-			batchCounter++
+			return sd, nil
+		})
+		sharedDrives = append(sharedDrives, WorkerPool.Values(results)...)
+		if err := WorkerPool.Incomplete(receiver.Ctx, "shared drives", results); err != nil {
+			return sharedDrives, err
 		}
 
 		log.Println("Shared Drive thus far:", len(sharedDrives))
@@ -154,21 +143,25 @@ func (receiver *DriveAPI) GetAllDrives() []*SharedDrive {
 		}
 		drivesListCall.PageToken(drivesList.NextPageToken)
 	}
-	return sharedDrives
+	return sharedDrives, nil
 }
 
 // GetFilePermissions This method is used to get the permissions for a file
-func (receiver *DriveAPI) GetFilePermissions(fileId string) []*drive.Permission {
+func (receiver *DriveAPI) GetFilePermissions(fileId string) ([]*drive.Permission, error) {
 	msg := fmt.Sprintf("Getting permissions for[%s]", fileId)
 	defer func() { log.Println(msg) }()
-	request := receiver.Service.Permissions.List(fileId).Fields("*").SupportsAllDrives(true).
+	request := receiver.Service.Permissions.List(fileId).Fields(receiver.Fields.Get(PermissionsListCall)).SupportsAllDrives(true).
 		SupportsTeamDrives(true).UseDomainAdminAccess(true).PageSize(100)
 	var permissions []*drive.Permission
 	for {
-		permissionList, err := request.Do()
+		var permissionList *drive.PermissionList
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			permissionList, err = request.Context(receiver.Ctx).Do()
+			return err
+		})
 		if err != nil {
-			log.Println(err.Error())
-			panic(err)
+			msg += " - " + err.Error()
+			return nil, err
 		}
 		permissions = append(permissions, permissionList.Permissions...)
 		if permissionList.NextPageToken == "" {
@@ -177,7 +170,7 @@ func (receiver *DriveAPI) GetFilePermissions(fileId string) []*drive.Permission
 		request.PageToken(permissionList.NextPageToken)
 	}
 	msg += fmt.Sprintf(" - %d permissions found", len(permissions))
-	return permissions
+	return permissions, nil
 }
 
 // SharedDrive is a struct that contains the metadata and permissions for a shared drive
@@ -187,6 +180,8 @@ type SharedDrive struct {
 	Groups      []*drive.Permission
 	Users       []*drive.Permission
 	Domain      string
+	// Err is the error of the permissions request, the drive has no permissions when it is set
+	Err error
 }
 
 // GetFiles returns a list of files in the user's drive
@@ -197,30 +192,33 @@ func (receiver *DriveAPI) GetFiles(q string) ([]*drive.File, error) {
 	// Get the files in the user's drive and add them to the list
 	for pt := ""; ; {
 		// Get the files
-		res, err := receiver.Service.Files.List().Q(q).PageSize(1000).PageToken(pt).Fields("*").Do()
+		page, nextPageToken, err := receiver.GetFilesPage(q, pt)
 		if err != nil {
-			if strings.Contains(err.Error(), "quota") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), time.Duration(receiver.SleepTime))
-				time.Sleep(time.Duration(receiver.SleepTime) * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				continue
-			} else {
-				return nil, err
-			}
+			return nil, err
 		}
 
 		// Add the files to the list
-		files = append(files, res.Files...)
+		files = append(files, page...)
 		log.Printf("User %s files thus far: %d", receiver.Subject, len(files))
 
 		// If there is no next page, break the loop
-		pt = res.NextPageToken
+		pt = nextPageToken
 		if pt == "" {
 			break
 		}
 	}
 	return files, nil
 }
+
+// GetFilesPage returns a single page of the files matching the query and the token of the next page, empty on the last page
+func (receiver *DriveAPI) GetFilesPage(q, pageToken string) ([]*drive.File, string, error) {
+	var res *drive.FileList
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		res, err = receiver.Service.Files.List().Q(q).PageSize(1000).PageToken(pageToken).Fields(receiver.Fields.Get(FilesListCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Files, res.NextPageToken, nil
+}
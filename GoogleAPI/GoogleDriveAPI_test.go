@@ -0,0 +1,110 @@
+package GoogleAPI_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+	"net/http"
+	"testing"
+)
+
+// testDelegationKey returns a service account key signing the JWTs sent to the fake token endpoint
+func testDelegationKey(t *testing.T) []byte {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	keyData, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "delegation@project-a.iam.gserviceaccount.com",
+		"private_key": string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})),
+		"token_uri": "https://oauth2.googleapis.com/token",
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return keyData
+}
+
+func TestGetAllDrives(t *testing.T) {
+	server := newTestServer(t)
+	driveAPI := GoogleAPI.NewDriveAPI(server.Client(), 0, context.Background())
+
+	sharedDrives, err := driveAPI.GetAllDrives()
+	if err != nil {
+		t.Fatalf("GetAllDrives returned an error: %v", err)
+	}
+	if len(sharedDrives) != 3 {
+		t.Fatalf("GetAllDrives returned %d drives, want 3", len(sharedDrives))
+	}
+	for _, sharedDrive := range sharedDrives {
+		if sharedDrive.MetaData.Id != "d1" {
+			continue
+		}
+		if len(sharedDrive.Permissions) != 3 || len(sharedDrive.Users) != 1 || len(sharedDrive.Groups) != 1 || sharedDrive.Domain != "example.com" {
+			t.Errorf("GetAllDrives d1 = %d permissions, %d users, %d groups, domain %q",
+				len(sharedDrive.Permissions), len(sharedDrive.Users), len(sharedDrive.Groups), sharedDrive.Domain)
+		}
+	}
+}
+
+func TestGetAllDrivesKeepsDrivesWithoutPermissions(t *testing.T) {
+	server := newTestServer(t)
+	driveAPI := GoogleAPI.NewDriveAPI(server.Client(), 0, context.Background())
+	server.InjectError("/drive/v3/files/d2/permissions", http.StatusNotFound, "notFound", -1)
+
+	sharedDrives, err := driveAPI.GetAllDrives()
+	if err != nil {
+		t.Fatalf("GetAllDrives returned an error: %v", err)
+	}
+	if len(sharedDrives) != 3 {
+		t.Fatalf("GetAllDrives returned %d drives, want 3", len(sharedDrives))
+	}
+	for _, sharedDrive := range sharedDrives {
+		if failed := sharedDrive.Err != nil; failed != (sharedDrive.MetaData.Id == "d2") {
+			t.Errorf("GetAllDrives %s error = %v", sharedDrive.MetaData.Id, sharedDrive.Err)
+		}
+	}
+}
+
+func TestGetFilesImpersonatesUser(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+	jwt := GoogleAPI.GetJWTClient("alice@example.com", testDelegationKey(t), []string{drive.DriveReadonlyScope}, ctx)
+	driveAPI := GoogleAPI.NewDriveAPI(jwt, 0, ctx)
+
+	files, err := driveAPI.GetFiles("mimeType='application/vnd.google-apps.script' AND 'me' in owners")
+	if err != nil {
+		t.Fatalf("GetFiles: %v", err)
+	}
+	if len(files) != 1 || files[0].Id != "f1" {
+		t.Errorf("GetFiles = %v, want the script owned by alice", files)
+	}
+}
+
+func TestUploadFile(t *testing.T) {
+	server := newTestServer(t)
+	driveAPI := GoogleAPI.NewDriveAPI(server.Client(), 0, context.Background())
+	data := bytes.Repeat([]byte("report"), 1024)
+
+	file, err := driveAPI.UploadFile(data, "report.zip", "folder-1")
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if file.Size != int64(len(data)) || file.WebViewLink == "" {
+		t.Errorf("UploadFile = %+v, want %d bytes and a link", file, len(data))
+	}
+	uploads := server.Tenant().Uploads
+	if len(uploads) != 1 || !bytes.Equal(uploads[0].Data, data) || uploads[0].MetaData.Parents[0] != "folder-1" {
+		t.Errorf("UploadFile stored %d uploads, want the report in folder-1", len(uploads))
+	}
+}
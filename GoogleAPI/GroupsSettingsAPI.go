@@ -0,0 +1,49 @@
+package GoogleAPI
+
+import (
+	"context"
+	"google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/option"
+	"log"
+	"net/http"
+	"time"
+)
+
+// GroupsSettingsAPI This is the struct that is used to interact with the Groups Settings API
+type GroupsSettingsAPI struct {
+	Service *groupssettings.Service
+	Retry   *RetryPolicy
+	// Fields are the field masks of the calls, the zero value sends the DefaultFieldMasks
+	Fields FieldMasks
+	// Ctx is the context of every request, cancelling it stops the retries
+	Ctx context.Context
+}
+
+// NewGroupsSettingsAPI This method is used to create a new GroupsSettingsAPI
+func NewGroupsSettingsAPI(client *http.Client, sleepTime int, ctx context.Context) *GroupsSettingsAPI {
+	// Create a new groups settings service
+	service, err := groupssettings.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		log.Println(err.Error())
+		panic(err)
+	}
+
+	return &GroupsSettingsAPI{
+		Service: service,
+		Retry:   NewRetryPolicy(time.Duration(sleepTime) * time.Second),
+		Ctx:     ctx,
+	}
+}
+
+// GetGroupSettings This method gets the joining, posting and visibility settings of a group
+func (receiver *GroupsSettingsAPI) GetGroupSettings(groupEmail string) (*groupssettings.Groups, error) {
+	var settings *groupssettings.Groups
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		settings, err = receiver.Service.Groups.Get(groupEmail).Fields(receiver.Fields.Get(GroupSettingsGetCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
@@ -0,0 +1,24 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"testing"
+)
+
+func TestGetGroupSettings(t *testing.T) {
+	server := newTestServer(t)
+	groupsSettingsAPI := GoogleAPI.NewGroupsSettingsAPI(server.Client(), 0, context.Background())
+
+	settings, err := groupsSettingsAPI.GetGroupSettings("admins@example.com")
+	if err != nil {
+		t.Fatalf("GetGroupSettings: %v", err)
+	}
+	if settings.WhoCanJoin != "ANYONE_CAN_JOIN" || settings.AllowExternalMembers != "true" {
+		t.Errorf("GetGroupSettings = %+v, want the settings of admins@example.com", settings)
+	}
+
+	if _, err := groupsSettingsAPI.GetGroupSettings("missing@example.com"); err == nil {
+		t.Error("GetGroupSettings of a missing group succeeded")
+	}
+}
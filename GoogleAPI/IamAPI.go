@@ -2,20 +2,21 @@ package GoogleAPI
 
 import (
 	"context"
-	"fmt"
 	"google.golang.org/api/iam/v1"
 	"google.golang.org/api/option"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 )
 
 // IamAPI is a wrapper for the Firestore client
 type IamAPI struct {
-	Service   *iam.Service
-	SleepTime int
-	MaxTries  int
+	Service *iam.Service
+	Retry   *RetryPolicy
+	// Ctx is the context of every request, cancelling it stops the retries
+	Ctx context.Context
+	// Fields are the field masks of the calls, the zero value sends the minimal masks
+	Fields FieldMasks
 }
 
 // NewIamAPI returns a new IamAPI
@@ -26,12 +27,13 @@ func NewIamAPI(client *http.Client, sleepTime int, ctx context.Context) *IamAPI
 	// Create a Firestore client
 	service, err := iam.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		log.Fatalf("Failed to create client: %v", err)
+		log.Println(err.Error())
+		panic(err)
 	}
 	// Set the Firestore client
 	newAPI.Service = service
-	newAPI.SleepTime = sleepTime
-	newAPI.MaxTries = 10
+	newAPI.Retry = NewRetryPolicy(time.Duration(sleepTime) * time.Second)
+	newAPI.Ctx = ctx
 	return newAPI
 }
 
@@ -51,8 +53,6 @@ func (receiver *IamAPI) GetProjectServiceAccounts(projectNumber string) ([]*GCPS
 	// Create a slice of GCPServiceAccount
 	var gcpServiceAccounts []*GCPServiceAccount
 
-	// Get the number of tries
-	tryCounter := 0
 	// Get the list of service accounts
 	for {
 		//Requesting the following scopes:
@@ -63,20 +63,13 @@ func (receiver *IamAPI) GetProjectServiceAccounts(projectNumber string) ([]*GCPS
 		//Owner
 
 		// Highly unlikely pagination will be needed as the number of service accounts is limited to 100
-		res, err := receiver.Service.Projects.ServiceAccounts.List(projectNumber).Fields("*").Do()
+		var res *iam.ListServiceAccountsResponse
+		err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+			res, err = receiver.Service.Projects.ServiceAccounts.List(projectNumber).Fields(receiver.Fields.Get(ServiceAccountsListCall)).Context(receiver.Ctx).Do()
+			return err
+		})
 		if err != nil {
-			if strings.Contains(err.Error(), "quota") {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), time.Duration(receiver.SleepTime))
-				time.Sleep(time.Duration(receiver.SleepTime) * time.Second)
-				continue
-			} else if strings.Contains(err.Error(), "500") && tryCounter < receiver.MaxTries {
-				fmt.Printf("%s, sleeping for %d seconds ...", err.Error(), 60)
-				time.Sleep(time.Second * 60)
-				tryCounter++
-				continue
-			} else {
-				return nil, err
-			}
+			return nil, err
 		}
 
 		// Check if there are any service accounts
@@ -100,3 +93,22 @@ func (receiver *IamAPI) GetProjectServiceAccounts(projectNumber string) ([]*GCPS
 	return gcpServiceAccounts, nil
 
 }
+
+// GetServiceAccount returns the service account of any project with the given unique id or email
+func (receiver *IamAPI) GetServiceAccount(uniqueId string) (*GCPServiceAccount, error) {
+	var account *iam.ServiceAccount
+	err := receiver.Retry.Do(receiver.Ctx, func() (err error) {
+		// The "-" project lets the API find the project of the account
+		account, err = receiver.Service.Projects.ServiceAccounts.Get("projects/-/serviceAccounts/" + uniqueId).Fields(receiver.Fields.Get(ServiceAccountsGetCall)).Context(receiver.Ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GCPServiceAccount{
+		Email:          account.Email,
+		Oauth2ClientId: account.Oauth2ClientId,
+		ProjectId:      account.ProjectId,
+		UniqueId:       account.UniqueId,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"errors"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"google.golang.org/api/googleapi"
+	"testing"
+)
+
+func TestGetProjectServiceAccounts(t *testing.T) {
+	server := newTestServer(t)
+	iamAPI := GoogleAPI.NewIamAPI(server.Client(), 0, context.Background())
+
+	accounts, err := iamAPI.GetProjectServiceAccounts("project-a")
+	if err != nil {
+		t.Fatalf("GetProjectServiceAccounts: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Email != "sa@project-a.iam.gserviceaccount.com" {
+		t.Errorf("GetProjectServiceAccounts = %v, want the project-a service account", accounts)
+	}
+
+	if _, err := iamAPI.GetProjectServiceAccounts("missing"); err == nil {
+		t.Error("GetProjectServiceAccounts(missing) succeeded, want a 404")
+	}
+}
+
+func TestGetServiceAccount(t *testing.T) {
+	server := newTestServer(t)
+	iamAPI := GoogleAPI.NewIamAPI(server.Client(), 0, context.Background())
+
+	account, err := iamAPI.GetServiceAccount("123")
+	if err != nil {
+		t.Fatalf("GetServiceAccount: %v", err)
+	}
+	if account.Email != "sa@project-a.iam.gserviceaccount.com" || account.ProjectId != "project-a" {
+		t.Errorf("GetServiceAccount = %+v, want the project-a service account", account)
+	}
+
+	_, err = iamAPI.GetServiceAccount("sa-unique-id")
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != 404 {
+		t.Errorf("GetServiceAccount(sa-unique-id) = %v, want a 404", err)
+	}
+}
@@ -0,0 +1,214 @@
+package GoogleAPI
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	directory "google.golang.org/api/admin/directory/v1"
+	"sort"
+	"strings"
+)
+
+// Membership This is an effective membership of a member in a group, either direct or inherited through nested groups
+type Membership struct {
+	// Group is the email of the group the member belongs to
+	Group string `json:"group"`
+	// Member is the email of the member, or its id when it has no email like the CUSTOMER members
+	Member string `json:"member"`
+	// Type is the type of the member: USER, GROUP or CUSTOMER
+	Type string `json:"type"`
+	// Role is the role of the member in the group it was added to
+	Role   string `json:"role"`
+	Status string `json:"status"`
+	// Depth is 1 for a direct member and the number of groups crossed for an inherited member
+	Depth int `json:"depth"`
+	// Path are the groups from Group down to the group the member was added to
+	Path []string `json:"path"`
+}
+
+// Inherited returns true when the member was not added to the group directly
+func (receiver *Membership) Inherited() bool {
+	return receiver.Depth > 1
+}
+
+// MembershipGraph This is the graph of the direct members of the groups, used to expand the groups nested in other groups.
+// The effective members are cached, so the graph must not be used by several goroutines at the same time.
+type MembershipGraph struct {
+	// Members are the direct members by lowercase group email
+	Members map[string][]*directory.Member
+	// Errors are the errors of the groups whose members could not be listed, their members are missing from the graph
+	Errors map[string]error
+	// Cycles are the groups that contain themselves through nested groups, the first group is repeated at the end of the path
+	Cycles [][]string
+
+	effective map[string][]*Membership
+}
+
+// NewMembershipGraph returns the graph of the given direct members by group email and detects its cycles
+func NewMembershipGraph(members map[string][]*directory.Member) *MembershipGraph {
+	graph := &MembershipGraph{
+		Members:   make(map[string][]*directory.Member, len(members)),
+		Errors:    make(map[string]error),
+		effective: make(map[string][]*Membership),
+	}
+	for group, groupMembers := range members {
+		graph.Members[strings.ToLower(group)] = groupMembers
+	}
+	graph.Cycles = graph.findCycles()
+	return graph
+}
+
+// GetMembershipGraph This method lists the direct members of every group with the worker pool and returns their graph.
+// The groups whose members cannot be listed are in the Errors of the graph, the graph is returned with a WorkerPool.IncompleteError when the context is done.
+func (receiver *DirectoryAPI) GetMembershipGraph(groups []*directory.Group) (*MembershipGraph, error) {
+	results := WorkerPool.Run(receiver.Ctx, groups, WorkerPool.Options{
+		Concurrency: receiver.Concurrency,
+		Progress:    WorkerPool.LogProgress("Groups Members", 100),
+	}, func(ctx context.Context, group *directory.Group) ([]*directory.Member, error) {
+		return receiver.GetGroupMembers(group, "")
+	})
+
+	members := make(map[string][]*directory.Member)
+	for _, result := range results {
+		if result.Err == nil {
+			members[groups[result.Index].Email] = result.Value
+		}
+	}
+	graph := NewMembershipGraph(members)
+	if incomplete := WorkerPool.Incomplete(receiver.Ctx, "groups", results); incomplete != nil {
+		return graph, incomplete
+	}
+	for _, result := range WorkerPool.Errors(results) {
+		graph.Errors[strings.ToLower(groups[result.Index].Email)] = result.Err
+	}
+	return graph, nil
+}
+
+// Groups returns the sorted emails of the groups of the graph
+func (receiver *MembershipGraph) Groups() []string {
+	groups := make([]string, 0, len(receiver.Members))
+	for group := range receiver.Members {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Effective returns the direct and inherited members of a group, expanded breadth first so that every member
+// keeps its shortest path. The nested groups that are not in the graph, like the groups of other domains, are not expanded.
+func (receiver *MembershipGraph) Effective(group string) []*Membership {
+	group = strings.ToLower(group)
+	if memberships, ok := receiver.effective[group]; ok {
+		return memberships
+	}
+
+	type nested struct {
+		group string
+		path  []string
+	}
+	var memberships []*Membership
+	queue := []nested{{group, []string{group}}}
+	expanded := map[string]bool{group: true}
+	seen := map[string]bool{group: true}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, member := range receiver.Members[current.group] {
+			key := memberKey(member)
+			if !seen[key] {
+				seen[key] = true
+				memberships = append(memberships, &Membership{
+					Group:  group,
+					Member: key,
+					Type:   member.Type,
+					Role:   member.Role,
+					Status: member.Status,
+					Depth:  len(current.path),
+					Path:   current.path,
+				})
+			}
+			if _, ok := receiver.Members[key]; ok && member.Type == "GROUP" && !expanded[key] {
+				expanded[key] = true
+				path := append(append([]string{}, current.path...), key)
+				queue = append(queue, nested{key, path})
+			}
+		}
+	}
+	receiver.effective[group] = memberships
+	return memberships
+}
+
+// ByMember returns the effective memberships of every member by member email, for example the groups a user inherits
+func (receiver *MembershipGraph) ByMember() map[string][]*Membership {
+	byMember := make(map[string][]*Membership)
+	for _, group := range receiver.Groups() {
+		for _, membership := range receiver.Effective(group) {
+			byMember[membership.Member] = append(byMember[membership.Member], membership)
+		}
+	}
+	return byMember
+}
+
+// Parents returns the sorted emails of the groups the group is a direct member of
+func (receiver *MembershipGraph) Parents(group string) []string {
+	group = strings.ToLower(group)
+	var parents []string
+	for _, parent := range receiver.Groups() {
+		for _, member := range receiver.Members[parent] {
+			if member.Type == "GROUP" && memberKey(member) == group {
+				parents = append(parents, parent)
+				break
+			}
+		}
+	}
+	return parents
+}
+
+// findCycles returns a path for every nested group leading back to a group being expanded, with a depth first search
+func (receiver *MembershipGraph) findCycles() [][]string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	var cycles [][]string
+	state := make(map[string]int)
+	var stack []string
+	var visit func(group string)
+	visit = func(group string) {
+		state[group] = visiting
+		stack = append(stack, group)
+		for _, member := range receiver.Members[group] {
+			key := memberKey(member)
+			if _, ok := receiver.Members[key]; !ok || member.Type != "GROUP" {
+				continue
+			}
+			switch state[key] {
+			case visiting:
+				// The group is already on the stack, the stack from it is the cycle
+				for i := range stack {
+					if stack[i] == key {
+						cycles = append(cycles, append(append([]string{}, stack[i:]...), key))
+						break
+					}
+				}
+			case 0:
+				visit(key)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[group] = visited
+	}
+	for _, group := range receiver.Groups() {
+		if state[group] == 0 {
+			visit(group)
+		}
+	}
+	return cycles
+}
+
+// memberKey returns the lowercase email of a member, or its id when it has no email
+func memberKey(member *directory.Member) string {
+	if member.Email == "" {
+		return member.Id
+	}
+	return strings.ToLower(member.Email)
+}
@@ -0,0 +1,72 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	directory "google.golang.org/api/admin/directory/v1"
+	"reflect"
+	"testing"
+)
+
+func TestGetMembershipGraph(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+	groups, err := directoryAPI.QueryGroups("")
+	if err != nil {
+		t.Fatalf("QueryGroups: %v", err)
+	}
+
+	graph, err := directoryAPI.GetMembershipGraph(groups)
+	if err != nil {
+		t.Fatalf("GetMembershipGraph: %v", err)
+	}
+	if len(graph.Cycles) != 0 {
+		t.Errorf("GetMembershipGraph found cycles %v, want none", graph.Cycles)
+	}
+
+	// The members of admins@example.com are inherited by all@example.com through it
+	memberships := map[string]*GoogleAPI.Membership{}
+	for _, membership := range graph.Effective("all@example.com") {
+		memberships[membership.Member] = membership
+	}
+	if len(memberships) != 5 {
+		t.Errorf("Effective(all@example.com) returned %d members, want 5", len(memberships))
+	}
+	if alice := memberships["alice@example.com"]; alice == nil || alice.Inherited() {
+		t.Errorf("Effective(all@example.com) alice = %+v, want a direct member", alice)
+	}
+	partner := memberships["partner@other.org"]
+	if partner == nil || partner.Depth != 2 || !reflect.DeepEqual(partner.Path, []string{"all@example.com", "admins@example.com"}) {
+		t.Errorf("Effective(all@example.com) partner = %+v, want inherited through admins@example.com", partner)
+	}
+
+	if groups := graph.ByMember()["admin@example.com"]; len(groups) != 2 {
+		t.Errorf("ByMember(admin@example.com) returned %d groups, want 2", len(groups))
+	}
+	if parents := graph.Parents("admins@example.com"); !reflect.DeepEqual(parents, []string{"all@example.com"}) {
+		t.Errorf("Parents(admins@example.com) = %v, want all@example.com", parents)
+	}
+}
+
+func TestMembershipGraphCycles(t *testing.T) {
+	graph := GoogleAPI.NewMembershipGraph(map[string][]*directory.Member{
+		"a@example.com": {{Email: "b@example.com", Type: "GROUP"}, {Email: "user@example.com", Type: "USER"}},
+		"b@example.com": {{Email: "C@example.com", Type: "GROUP"}},
+		"c@example.com": {{Email: "a@example.com", Type: "GROUP"}},
+	})
+
+	want := [][]string{{"a@example.com", "b@example.com", "c@example.com", "a@example.com"}}
+	if !reflect.DeepEqual(graph.Cycles, want) {
+		t.Errorf("Cycles = %v, want %v", graph.Cycles, want)
+	}
+
+	// Every group of the cycle inherits the other groups and the user once, but not itself
+	memberships := graph.Effective("b@example.com")
+	if len(memberships) != 3 {
+		t.Fatalf("Effective(b@example.com) returned %d members, want 3: %v", len(memberships), memberships)
+	}
+	user := memberships[2]
+	if user.Member != "user@example.com" || user.Depth != 3 || !reflect.DeepEqual(user.Path, []string{"b@example.com", "c@example.com", "a@example.com"}) {
+		t.Errorf("Effective(b@example.com) user = %+v", user)
+	}
+}
@@ -0,0 +1,138 @@
+package GoogleAPI
+
+import (
+	directory "google.golang.org/api/admin/directory/v1"
+	"sort"
+	"strings"
+)
+
+// RootOrgUnitPath This is the path of the root organizational unit of the customer
+const RootOrgUnitPath = "/"
+
+// OrgUnit This is an organizational unit of the tree with the users placed directly in it
+type OrgUnit struct {
+	Id               string
+	Name             string
+	Path             string
+	ParentPath       string
+	Description      string
+	BlockInheritance bool
+	// Depth is the number of units above the unit, the root is 0
+	Depth    int
+	Children []*OrgUnit
+	// Users are the users placed directly in the unit, not in its children
+	Users []*directory.User
+}
+
+// Walk calls fn on the unit and every unit below it, parents first and children sorted by path
+func (receiver *OrgUnit) Walk(fn func(orgUnit *OrgUnit)) {
+	fn(receiver)
+	for _, child := range receiver.Children {
+		child.Walk(fn)
+	}
+}
+
+// SubtreeUsers returns the users of the unit and of every unit below it
+func (receiver *OrgUnit) SubtreeUsers() []*directory.User {
+	var users []*directory.User
+	receiver.Walk(func(orgUnit *OrgUnit) {
+		users = append(users, orgUnit.Users...)
+	})
+	return users
+}
+
+// OrgUnitTree This is the tree of the organizational units of the customer, from the root unit "/"
+type OrgUnitTree struct {
+	Root  *OrgUnit
+	paths map[string]*OrgUnit
+	ids   map[string]*OrgUnit
+}
+
+// NewOrgUnitTree builds the tree of the units and places the users in them.
+// The Directory API does not list the root unit, and a unit missing from the list, for example created during the listing, is added with its path.
+func NewOrgUnitTree(orgUnits []*directory.OrgUnit, users []*directory.User) *OrgUnitTree {
+	tree := &OrgUnitTree{
+		Root:  &OrgUnit{Name: RootOrgUnitPath, Path: RootOrgUnitPath},
+		paths: make(map[string]*OrgUnit),
+		ids:   make(map[string]*OrgUnit),
+	}
+	tree.paths[RootOrgUnitPath] = tree.Root
+	for _, orgUnit := range orgUnits {
+		unit := tree.unit(orgUnit.OrgUnitPath)
+		unit.Id = orgUnitId(orgUnit.OrgUnitId)
+		unit.Name = orgUnit.Name
+		unit.Description = orgUnit.Description
+		unit.BlockInheritance = orgUnit.BlockInheritance
+		tree.ids[unit.Id] = unit
+		if orgUnit.ParentOrgUnitPath == RootOrgUnitPath && tree.Root.Id == "" {
+			tree.Root.Id = orgUnitId(orgUnit.ParentOrgUnitId)
+			tree.ids[tree.Root.Id] = tree.Root
+		}
+	}
+	for _, user := range users {
+		unit := tree.unit(user.OrgUnitPath)
+		unit.Users = append(unit.Users, user)
+	}
+	tree.Root.Walk(func(orgUnit *OrgUnit) {
+		sort.Slice(orgUnit.Children, func(i, j int) bool {
+			return orgUnit.Children[i].Path < orgUnit.Children[j].Path
+		})
+	})
+	return tree
+}
+
+// unit returns the unit of the path, adding it and its missing parents to the tree
+func (receiver *OrgUnitTree) unit(path string) *OrgUnit {
+	path = cleanOrgUnitPath(path)
+	key := strings.ToLower(path)
+	if unit, ok := receiver.paths[key]; ok {
+		return unit
+	}
+	parentPath := RootOrgUnitPath
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		parentPath = path[:i]
+	}
+	parent := receiver.unit(parentPath)
+	unit := &OrgUnit{Name: path[strings.LastIndex(path, "/")+1:], Path: path, ParentPath: parent.Path, Depth: parent.Depth + 1}
+	parent.Children = append(parent.Children, unit)
+	receiver.paths[key] = unit
+	return unit
+}
+
+// Find returns the unit of the path, nil when the tree has no such unit
+func (receiver *OrgUnitTree) Find(path string) *OrgUnit {
+	return receiver.paths[strings.ToLower(cleanOrgUnitPath(path))]
+}
+
+// FindById returns the unit of the id, with or without the "id:" prefix, nil when the tree has no such unit
+func (receiver *OrgUnitTree) FindById(id string) *OrgUnit {
+	return receiver.ids[orgUnitId(id)]
+}
+
+// Walk calls fn on every unit of the tree, parents first
+func (receiver *OrgUnitTree) Walk(fn func(orgUnit *OrgUnit)) {
+	receiver.Root.Walk(fn)
+}
+
+// InOrgUnit returns true when the path is the subtree or a unit below it, the root or an empty subtree contains every path
+func InOrgUnit(path, subtree string) bool {
+	path, subtree = strings.ToLower(cleanOrgUnitPath(path)), strings.ToLower(cleanOrgUnitPath(subtree))
+	return subtree == RootOrgUnitPath || path == subtree || strings.HasPrefix(path, subtree+"/")
+}
+
+// cleanOrgUnitPath returns the path without its trailing slash, the root for an empty path
+func cleanOrgUnitPath(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		return RootOrgUnitPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// orgUnitId returns the id of a unit without the "id:" prefix of the OrgUnit resources
+func orgUnitId(id string) string {
+	return strings.TrimPrefix(id, "id:")
+}
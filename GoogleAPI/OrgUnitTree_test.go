@@ -0,0 +1,67 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	directory "google.golang.org/api/admin/directory/v1"
+	"testing"
+)
+
+func TestGetOrgUnitTree(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	tree, err := directoryAPI.GetOrgUnitTree()
+	if err != nil {
+		t.Fatalf("GetOrgUnitTree: %v", err)
+	}
+	if tree.Root.Id != "root" || len(tree.Root.Children) != 1 || len(tree.Root.SubtreeUsers()) != 5 {
+		t.Errorf("GetOrgUnitTree root = %+v, want the root with Engineering and every user", tree.Root)
+	}
+	engineering := tree.Find("/engineering/")
+	if engineering == nil || engineering.Id != "ou1" || len(engineering.Users) != 1 || engineering.Users[0].PrimaryEmail != "alice@example.com" {
+		t.Fatalf("Find(/engineering/) = %+v, want Engineering with alice@example.com", engineering)
+	}
+	platform := tree.FindById("id:ou2")
+	if platform == nil || platform.ParentPath != "/Engineering" || platform.Depth != 2 || !platform.BlockInheritance || len(platform.Users) != 0 {
+		t.Errorf("FindById(id:ou2) = %+v, want Platform below Engineering", platform)
+	}
+	if len(tree.Root.Users) != 4 {
+		t.Errorf("GetOrgUnitTree placed %d users in the root, want 4", len(tree.Root.Users))
+	}
+}
+
+func TestOrgUnitTreeAddsMissingUnits(t *testing.T) {
+	users := []*directory.User{{PrimaryEmail: "eve@example.com", OrgUnitPath: "/Sales/West"}}
+	tree := GoogleAPI.NewOrgUnitTree(nil, users)
+
+	var paths []string
+	tree.Walk(func(orgUnit *GoogleAPI.OrgUnit) {
+		paths = append(paths, orgUnit.Path)
+	})
+	if len(paths) != 3 || paths[0] != "/" || paths[1] != "/Sales" || paths[2] != "/Sales/West" {
+		t.Errorf("Walk = %v, want the root, /Sales and /Sales/West", paths)
+	}
+	if users := tree.Find("/Sales").SubtreeUsers(); len(users) != 1 {
+		t.Errorf("SubtreeUsers(/Sales) = %v, want eve@example.com", users)
+	}
+}
+
+func TestInOrgUnit(t *testing.T) {
+	tests := []struct {
+		path, subtree string
+		want          bool
+	}{
+		{"/Engineering", "", true},
+		{"", "/", true},
+		{"/Engineering/Platform", "/Engineering", true},
+		{"/engineering", "/Engineering/", true},
+		{"/EngineeringOps", "/Engineering", false},
+		{"/", "/Engineering", false},
+	}
+	for _, test := range tests {
+		if got := GoogleAPI.InOrgUnit(test.path, test.subtree); got != test.want {
+			t.Errorf("InOrgUnit(%q, %q) = %v, want %v", test.path, test.subtree, got, test.want)
+		}
+	}
+}
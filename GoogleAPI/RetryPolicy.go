@@ -0,0 +1,166 @@
+package GoogleAPI
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/api/googleapi"
+	"log"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy This is the struct that describes how the API wrappers retry failed requests
+type RetryPolicy struct {
+	// MaxRetries is the number of retries allowed for a single call, the first attempt is not counted
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff, zero does not cap it, a Retry-After sent by the server may exceed it
+	MaxBackoff time.Duration
+	// Multiplier is applied to the backoff after every retry
+	Multiplier float64
+	// Jitter is the fraction of the backoff that is randomized, between 0 and 1
+	Jitter float64
+}
+
+// retryableReasons This is the list of 403 reasons that are quota errors rather than permission errors
+var retryableReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+	"quotaExceeded":         true,
+	"backendError":          true,
+}
+
+// NewRetryPolicy returns the default RetryPolicy starting with the given backoff
+func NewRetryPolicy(initialBackoff time.Duration) *RetryPolicy {
+	if initialBackoff <= 0 {
+		initialBackoff = time.Second
+	}
+	return &RetryPolicy{
+		MaxRetries:     10,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     2 * time.Minute,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+}
+
+// RetryOverride This is the fields of a RetryPolicy set by the configuration, a nil field keeps the value of the policy.
+// Unlike the zero value, a field set to zero overrides the policy, like a MaxRetries of 0 that disables the retries.
+type RetryOverride struct {
+	MaxRetries     *int
+	InitialBackoff *time.Duration
+	MaxBackoff     *time.Duration
+	Multiplier     *float64
+	Jitter         *float64
+}
+
+// Override copies the fields set in the override
+func (receiver *RetryPolicy) Override(override *RetryOverride) {
+	if override == nil {
+		return
+	}
+	if override.MaxRetries != nil {
+		receiver.MaxRetries = *override.MaxRetries
+	}
+	if override.InitialBackoff != nil {
+		receiver.InitialBackoff = *override.InitialBackoff
+	}
+	if override.MaxBackoff != nil {
+		receiver.MaxBackoff = *override.MaxBackoff
+	}
+	if override.Multiplier != nil {
+		receiver.Multiplier = *override.Multiplier
+	}
+	if override.Jitter != nil {
+		receiver.Jitter = *override.Jitter
+	}
+}
+
+// Do calls the function until it succeeds, fails with an error that is not retryable, the retry budget is spent or the context is done
+func (receiver *RetryPolicy) Do(ctx context.Context, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil {
+			return nil
+		}
+
+		// Check if the error is worth another attempt
+		retryable, retryAfter := IsRetryable(err)
+		if !retryable || attempt >= receiver.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		// Wait for the backoff or the time requested by the server
+		wait := receiver.Backoff(attempt)
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		log.Printf("%s, retrying in %s (retry %d of %d)...", err.Error(), wait.Round(time.Millisecond), attempt+1, receiver.MaxRetries)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff returns the jittered wait before the given retry, starting at zero
+func (receiver *RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(receiver.InitialBackoff) * math.Pow(receiver.Multiplier, float64(attempt))
+	if receiver.MaxBackoff > 0 && backoff > float64(receiver.MaxBackoff) {
+		backoff = float64(receiver.MaxBackoff)
+	}
+	// Randomize the last part of the backoff so concurrent workers do not retry in lockstep
+	backoff -= backoff * receiver.Jitter * rand.Float64()
+	return time.Duration(backoff)
+}
+
+// IsRetryable returns true if the request that failed with the error should be retried and how long the server asked to wait
+func IsRetryable(err error) (bool, time.Duration) {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		retryAfter := parseRetryAfter(apiErr.Header)
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			return true, retryAfter
+		case apiErr.Code >= http.StatusInternalServerError:
+			return true, retryAfter
+		case apiErr.Code == http.StatusForbidden:
+			for _, item := range apiErr.Errors {
+				if retryableReasons[item.Reason] {
+					return true, retryAfter
+				}
+			}
+		}
+		return false, 0
+	}
+
+	// Retry the network errors that are expected to go away
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true, 0
+	}
+	return false, 0
+}
+
+// parseRetryAfter returns the wait requested by a Retry-After header in seconds or as an HTTP date
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
@@ -0,0 +1,140 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"google.golang.org/api/googleapi"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// timeoutError This is a network error that timed out
+type timeoutError struct{ timeout bool }
+
+func (receiver *timeoutError) Error() string   { return "i/o timeout" }
+func (receiver *timeoutError) Timeout() bool   { return receiver.timeout }
+func (receiver *timeoutError) Temporary() bool { return receiver.timeout }
+
+// apiError returns a Google API error with the code, the reason of its first item and the Retry-After header when it is not empty
+func apiError(code int, reason, retryAfter string) *googleapi.Error {
+	apiErr := &googleapi.Error{Code: code, Header: http.Header{}}
+	if reason != "" {
+		apiErr.Errors = []googleapi.ErrorItem{{Reason: reason}}
+	}
+	if retryAfter != "" {
+		apiErr.Header.Set("Retry-After", retryAfter)
+	}
+	return apiErr
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"too many requests", apiError(http.StatusTooManyRequests, "", ""), true},
+		{"internal error", apiError(http.StatusInternalServerError, "", ""), true},
+		{"unavailable", apiError(http.StatusServiceUnavailable, "", ""), true},
+		{"rate limit", apiError(http.StatusForbidden, "rateLimitExceeded", ""), true},
+		{"user rate limit", apiError(http.StatusForbidden, "userRateLimitExceeded", ""), true},
+		{"quota", apiError(http.StatusForbidden, "quotaExceeded", ""), true},
+		{"backend error", apiError(http.StatusForbidden, "backendError", ""), true},
+		{"forbidden", apiError(http.StatusForbidden, "forbidden", ""), false},
+		{"forbidden without a reason", apiError(http.StatusForbidden, "", ""), false},
+		{"not found", apiError(http.StatusNotFound, "notFound", ""), false},
+		{"bad request", apiError(http.StatusBadRequest, "invalid", ""), false},
+		{"wrapped rate limit", fmt.Errorf("DirectoryAPI.GetUserTokens: %w", apiError(http.StatusForbidden, "rateLimitExceeded", "")), true},
+		{"network timeout", &timeoutError{timeout: true}, true},
+		{"network error", &timeoutError{timeout: false}, false},
+		{"other error", errors.New("unauthorized_client"), false},
+	}
+	for _, test := range tests {
+		if got, _ := GoogleAPI.IsRetryable(test.err); got != test.want {
+			t.Errorf("IsRetryable(%s) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIsRetryableRetryAfter(t *testing.T) {
+	date := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		name       string
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{"no header", "", 0, 0},
+		{"seconds", "7", 7 * time.Second, 7 * time.Second},
+		{"HTTP date", date, 28 * time.Second, 30 * time.Second},
+		{"invalid", "soon", 0, 0},
+	}
+	for _, test := range tests {
+		retryable, wait := GoogleAPI.IsRetryable(apiError(http.StatusTooManyRequests, "", test.retryAfter))
+		if !retryable || wait < test.min || wait > test.max {
+			t.Errorf("IsRetryable with Retry-After %s = %v, %s, want a wait between %s and %s", test.name, retryable, wait, test.min, test.max)
+		}
+	}
+
+	// The wait is only returned with a retryable error
+	if _, wait := GoogleAPI.IsRetryable(apiError(http.StatusNotFound, "", "7")); wait != 0 {
+		t.Errorf("IsRetryable of a 404 waits %s, want 0", wait)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := &GoogleAPI.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := policy.Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+
+	// A zero MaxBackoff does not cap the backoff
+	policy.MaxBackoff = 0
+	if got := policy.Backoff(10); got != 1024*time.Second {
+		t.Errorf("uncapped Backoff(10) = %s, want 1024s", got)
+	}
+
+	// The jitter randomizes the last part of the backoff
+	policy.MaxBackoff, policy.Jitter = 5*time.Second, 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Backoff(3); got < 2500*time.Millisecond || got > 5*time.Second {
+			t.Fatalf("jittered Backoff(3) = %s, want between 2.5s and 5s", got)
+		}
+	}
+}
+
+func TestOverride(t *testing.T) {
+	policy := GoogleAPI.NewRetryPolicy(time.Second)
+	policy.Override(nil)
+	if *policy != *GoogleAPI.NewRetryPolicy(time.Second) {
+		t.Errorf("Override(nil) changed the policy to %+v", policy)
+	}
+
+	// The zeros that are set override the policy, the fields not set keep it
+	maxRetries, jitter, maxBackoff := 0, 0.0, time.Minute
+	policy.Override(&GoogleAPI.RetryOverride{MaxRetries: &maxRetries, Jitter: &jitter, MaxBackoff: &maxBackoff})
+	want := GoogleAPI.NewRetryPolicy(time.Second)
+	want.MaxRetries, want.Jitter, want.MaxBackoff = 0, 0, time.Minute
+	if *policy != *want {
+		t.Errorf("Override = %+v, want %+v", policy, want)
+	}
+}
+
+func TestDoWithoutRetries(t *testing.T) {
+	policy := GoogleAPI.NewRetryPolicy(time.Millisecond)
+	maxRetries := 0
+	policy.Override(&GoogleAPI.RetryOverride{MaxRetries: &maxRetries})
+
+	calls := 0
+	err := policy.Do(context.Background(), func() error {
+		calls++
+		return apiError(http.StatusTooManyRequests, "", "")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Do made %d calls and returned %v, want a single failed call", calls, err)
+	}
+}
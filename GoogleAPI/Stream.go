@@ -0,0 +1,74 @@
+package GoogleAPI
+
+import (
+	"context"
+	directory "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/drive/v3"
+)
+
+// Item This is an item of a stream, or the error that ended the stream when Err is set
+type Item[T any] struct {
+	Value T
+	Err   error
+}
+
+// Stream fetches the pages in a goroutine and sends their items on the returned channel as soon as every page is received.
+// The channel is closed after the last page, or after an item holding the error of a page.
+// Once the context is done the pagination stops and the channel is closed, the readers check the context to tell it from the end of the stream.
+func Stream[T any](ctx context.Context, fetch func(pageToken string) ([]T, string, error)) <-chan Item[T] {
+	items := make(chan Item[T])
+	go func() {
+		defer close(items)
+		send := func(item Item[T]) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case items <- item:
+				return true
+			}
+		}
+		for pageToken := ""; ; {
+			page, nextPageToken, err := fetch(pageToken)
+			if err != nil {
+				send(Item[T]{Err: err})
+				return
+			}
+			for _, value := range page {
+				if !send(Item[T]{Value: value}) {
+					return
+				}
+			}
+			if pageToken = nextPageToken; pageToken == "" {
+				return
+			}
+		}
+	}()
+	return items
+}
+
+// StreamUsers This method is used to stream the users matching the query page by page, see Stream
+func (receiver *DirectoryAPI) StreamUsers(q string) <-chan Item[*directory.User] {
+	return Stream(receiver.Ctx, func(pageToken string) ([]*directory.User, string, error) {
+		return receiver.QueryUsersPage(q, pageToken)
+	})
+}
+
+// StreamGroups This method is used to stream the groups matching the query page by page, see Stream
+func (receiver *DirectoryAPI) StreamGroups(q string) <-chan Item[*directory.Group] {
+	return Stream(receiver.Ctx, func(pageToken string) ([]*directory.Group, string, error) {
+		return receiver.QueryGroupsPage(q, pageToken)
+	})
+}
+
+// StreamFiles This method is used to stream the files matching the query page by page, see Stream
+func (receiver *DriveAPI) StreamFiles(q string) <-chan Item[*drive.File] {
+	return Stream(receiver.Ctx, func(pageToken string) ([]*drive.File, string, error) {
+		return receiver.GetFilesPage(q, pageToken)
+	})
+}
+
+// StreamProjects This method is used to stream the projects page by page, see Stream
+func (receiver *CloudResourceManagerAPI) StreamProjects() <-chan Item[*cloudresourcemanager.Project] {
+	return Stream(receiver.Ctx, receiver.GetProjectsPage)
+}
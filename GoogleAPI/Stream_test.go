@@ -0,0 +1,130 @@
+package GoogleAPI_test
+
+import (
+	"context"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/GoogleAPI"
+	"net/http"
+	"testing"
+)
+
+func TestStreamUsers(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	// The users of the 3 pages are streamed in order
+	var emails []string
+	for item := range directoryAPI.StreamUsers("") {
+		if item.Err != nil {
+			t.Fatalf("StreamUsers: %v", item.Err)
+		}
+		emails = append(emails, item.Value.PrimaryEmail)
+	}
+	if len(emails) != 5 || emails[0] != "admin@example.com" || emails[4] != "dave@example.com" {
+		t.Errorf("StreamUsers = %v, want the 5 users in order", emails)
+	}
+}
+
+func TestStreamEndsWithPageError(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+	server.InjectError("/admin/directory/v1/groups", http.StatusForbidden, "forbidden", 1)
+
+	var errs []error
+	for item := range directoryAPI.StreamGroups("") {
+		errs = append(errs, item.Err)
+	}
+	if len(errs) != 1 || errs[0] == nil {
+		t.Errorf("StreamGroups errors = %v, want a single item with the injected 403", errs)
+	}
+}
+
+func TestStreamStopsWhenCancelled(t *testing.T) {
+	server := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, ctx)
+
+	// Stop reading after the first user, the stream is closed without fetching the other pages
+	users := directoryAPI.StreamUsers("")
+	<-users
+	cancel()
+	for range users {
+	}
+	if got := server.Requests("/admin/directory/v1/users"); got > 2 {
+		t.Errorf("StreamUsers made %d requests after the cancellation, want at most 2", got)
+	}
+}
+
+func TestStreamUsersAndToken(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+
+	count := 0
+	for item := range directoryAPI.StreamUsersAndToken("") {
+		if item.Err != nil {
+			t.Fatalf("StreamUsersAndToken: %v", item.Err)
+		}
+		count++
+		if user := item.Value; user.PrimaryEmail == "alice@example.com" && (len(user.Tokens) != 1 || user.Tokens[0].ClientId != "app-1") {
+			t.Errorf("StreamUsersAndToken tokens of %s = %v, want app-1", user.PrimaryEmail, user.Tokens)
+		}
+	}
+	if count != 5 {
+		t.Errorf("StreamUsersAndToken returned %d users, want 5", count)
+	}
+}
+
+func TestStreamFilesAndProjects(t *testing.T) {
+	server := newTestServer(t)
+	driveAPI := GoogleAPI.NewDriveAPI(server.Client(), 0, context.Background())
+	files := 0
+	for item := range driveAPI.StreamFiles("") {
+		if item.Err != nil {
+			t.Fatalf("StreamFiles: %v", item.Err)
+		}
+		files++
+	}
+	if files != 3 {
+		t.Errorf("StreamFiles returned %d files, want 3", files)
+	}
+
+	crmAPI := GoogleAPI.NewCloudResourceManagerAPI(server.Client(), 0, context.Background())
+	projects := 0
+	for item := range crmAPI.StreamProjects() {
+		if item.Err != nil {
+			t.Fatalf("StreamProjects: %v", item.Err)
+		}
+		projects++
+	}
+	if projects != 2 {
+		t.Errorf("StreamProjects returned %d projects, want 2", projects)
+	}
+}
+
+func TestStreamUsersAndTokenEndsWithPageError(t *testing.T) {
+	server := newTestServer(t)
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, context.Background())
+	server.InjectError("/admin/directory/v1/users", http.StatusForbidden, "forbidden", 1)
+
+	var errs []error
+	for item := range directoryAPI.StreamUsersAndToken("") {
+		errs = append(errs, item.Err)
+	}
+	if len(errs) != 1 || errs[0] == nil {
+		t.Errorf("StreamUsersAndToken errors = %v, want a single item with the injected 403", errs)
+	}
+}
+
+func TestStreamUsersAndTokenStopsWhenCancelled(t *testing.T) {
+	server := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	directoryAPI := GoogleAPI.NewDirectoryAPI(server.Client(), 0, ctx)
+
+	// The stream is closed once the pagination and the token listings stopped, run with -race
+	users := directoryAPI.StreamUsersAndToken("")
+	<-users
+	cancel()
+	for range users {
+	}
+}
@@ -0,0 +1,12 @@
+package Report
+
+import "time"
+
+// FormatMillis returns the RFC 3339 time of milliseconds since the epoch, empty when the time is not set.
+// The Directory API returns the creation and last use times of domains and credentials this way.
+func FormatMillis(millis int64) string {
+	if millis == 0 {
+		return ""
+	}
+	return time.UnixMilli(millis).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,18 @@
+package Report_test
+
+import (
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"testing"
+)
+
+func TestFormatMillis(t *testing.T) {
+	for millis, want := range map[int64]string{
+		0:             "",
+		1686700800000: "2023-06-14T00:00:00Z",
+		1686700800999: "2023-06-14T00:00:00Z",
+	} {
+		if got := Report.FormatMillis(millis); got != want {
+			t.Errorf("FormatMillis(%d) = %q, want %q", millis, got, want)
+		}
+	}
+}
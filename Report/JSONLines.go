@@ -0,0 +1,78 @@
+package Report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// JSONLinesSink This is a Sink that writes every report to a JSON Lines file in a directory, one object per row
+type JSONLinesSink struct {
+	Path string
+}
+
+// NewJSONLinesSink returns a new JSONLinesSink writing to the given directory
+func NewJSONLinesSink(path string) *JSONLinesSink {
+	return &JSONLinesSink{Path: path}
+}
+
+// Create creates the JSON Lines file of the report
+func (receiver *JSONLinesSink) Create(name string, headers []string) (Writer, error) {
+	file, err := os.Create(filepath.Join(receiver.Path, name+".jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Encode the keys once, the headers are the keys of every object
+	keys := make([][]byte, len(headers))
+	for i, header := range headers {
+		if keys[i], err = json.Marshal(header); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return &jsonLinesWriter{file: file, writer: bufio.NewWriter(file), keys: keys}, nil
+}
+
+// jsonLinesWriter This is the Writer of a single JSON Lines file
+type jsonLinesWriter struct {
+	file   *os.File
+	writer *bufio.Writer
+	keys   [][]byte
+}
+
+// Write adds the row as an object keeping the order of the headers, the nested values stay nested
+func (receiver *jsonLinesWriter) Write(row []any) error {
+	if len(row) != len(receiver.keys) {
+		return fmt.Errorf("row of %d values for %d headers", len(row), len(receiver.keys))
+	}
+	line := &bytes.Buffer{}
+	line.WriteByte('{')
+	for i, value := range row {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		line.Write(receiver.keys[i])
+		line.WriteByte(':')
+		line.Write(data)
+	}
+	line.WriteString("}\n")
+	_, err := receiver.writer.Write(line.Bytes())
+	return err
+}
+
+// Close flushes and closes the JSON Lines file
+func (receiver *jsonLinesWriter) Close() error {
+	if err := receiver.writer.Flush(); err != nil {
+		receiver.file.Close()
+		return err
+	}
+	return receiver.file.Close()
+}
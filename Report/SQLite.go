@@ -0,0 +1,138 @@
+package Report
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	// Register the pure Go SQLite driver
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteFileName This is the name of the database written by the SQLiteSink
+const SQLiteFileName = "reports.db"
+
+// SQLiteSink This is a Sink that writes every report to a table of a single SQLite database in a directory.
+// The columns have no declared type so every value keeps its own: integers, reals, text and the nested values as JSON text.
+type SQLiteSink struct {
+	Path string
+
+	once sync.Once
+	db   *sql.DB
+	err  error
+}
+
+// NewSQLiteSink returns a new SQLiteSink writing to the given directory, the database is created with the first report
+func NewSQLiteSink(path string) *SQLiteSink {
+	return &SQLiteSink{Path: path}
+}
+
+// open opens the database once
+func (receiver *SQLiteSink) open() (*sql.DB, error) {
+	receiver.once.Do(func() {
+		receiver.db, receiver.err = sql.Open("sqlite", filepath.Join(receiver.Path, SQLiteFileName))
+		if receiver.err == nil {
+			// A single connection writes the reports one transaction at a time
+			receiver.db.SetMaxOpenConns(1)
+		}
+	})
+	return receiver.db, receiver.err
+}
+
+// Create replaces the table of the report and starts the transaction its rows are written in
+func (receiver *SQLiteSink) Create(name string, headers []string) (Writer, error) {
+	db, err := receiver.open()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(headers))
+	placeholders := make([]string, len(headers))
+	for i, header := range headers {
+		columns[i] = quoteIdentifier(header)
+		placeholders[i] = "?"
+	}
+	statements := []string{
+		"DROP TABLE IF EXISTS " + quoteIdentifier(name),
+		fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(name), strings.Join(columns, ", ")),
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	insert, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdentifier(name), strings.Join(placeholders, ", ")))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return &sqliteWriter{tx: tx, insert: insert}, nil
+}
+
+// Close closes the database
+func (receiver *SQLiteSink) Close() error {
+	if receiver.db == nil {
+		return nil
+	}
+	return receiver.db.Close()
+}
+
+// sqliteWriter This is the Writer of a single table
+type sqliteWriter struct {
+	tx     *sql.Tx
+	insert *sql.Stmt
+}
+
+// Write inserts the row, the nested values are encoded as JSON
+func (receiver *sqliteWriter) Write(row []any) error {
+	values := make([]any, len(row))
+	for i, value := range row {
+		converted, err := sqliteValue(value)
+		if err != nil {
+			return err
+		}
+		values[i] = converted
+	}
+	_, err := receiver.insert.Exec(values...)
+	return err
+}
+
+// Close commits the rows of the table
+func (receiver *sqliteWriter) Close() error {
+	receiver.insert.Close()
+	return receiver.tx.Commit()
+}
+
+// sqliteValue returns the value stored in a column
+func sqliteValue(value any) (any, error) {
+	switch typed := value.(type) {
+	case nil, string, bool, int, int64, float64:
+		return typed, nil
+	case time.Time:
+		return typed.Format(time.RFC3339), nil
+	}
+	text, err := FormatCell(value)
+	if err != nil || text == "" {
+		return nil, err
+	}
+	// Keep the lists of strings as JSON arrays rather than comma separated
+	if list, ok := value.([]string); ok {
+		data, err := json.Marshal(list)
+		return string(data), err
+	}
+	return text, nil
+}
+
+// quoteIdentifier quotes a table or column name
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
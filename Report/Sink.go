@@ -0,0 +1,251 @@
+package Report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sink This is the interface the audits write their reports to
+type Sink interface {
+	// Create starts a new report with the given column headers
+	Create(name string, headers []string) (Writer, error)
+}
+
+// Writer This is the interface used to write the rows of a single report.
+// The values of a row are strings, booleans, numbers, times or nested values such as slices, maps and structs,
+// every sink stores them in its own way.
+type Writer interface {
+	// Write adds a row to the report, the values are in the order of the headers
+	Write(row []any) error
+	// Close flushes the report
+	Close() error
+}
+
+// sinkFactories This is the list of the output formats and the constructors of their sinks
+var sinkFactories = map[string]func(path string) Sink{
+	"csv":    func(path string) Sink { return NewCSVSink(path) },
+	"jsonl":  func(path string) Sink { return NewJSONLinesSink(path) },
+	"sqlite": func(path string) Sink { return NewSQLiteSink(path) },
+}
+
+// Formats returns the names of the supported output formats
+func Formats() []string {
+	var formats []string
+	for format := range sinkFactories {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// IsFormat returns true if the output format is supported
+func IsFormat(format string) bool {
+	_, ok := sinkFactories[format]
+	return ok
+}
+
+// NewSink returns the sink writing every report in each of the formats to the directory
+func NewSink(formats []string, path string) (Sink, error) {
+	var sinks []Sink
+	for _, format := range formats {
+		factory, ok := sinkFactories[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown output format %q", format)
+		}
+		sinks = append(sinks, factory(path))
+	}
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return &MultiSink{Sinks: sinks}, nil
+}
+
+// MultiSink This is a Sink that writes every report to all of its sinks
+type MultiSink struct {
+	Sinks []Sink
+}
+
+// Close closes the sinks that hold resources and returns the first error
+func (receiver *MultiSink) Close() error {
+	var first error
+	for _, sink := range receiver.Sinks {
+		if err := Close(sink); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close closes the sink if it holds resources, like the database of a SQLiteSink
+func Close(sink Sink) error {
+	if closer, ok := sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Create creates the report in every sink
+func (receiver *MultiSink) Create(name string, headers []string) (Writer, error) {
+	writer := &multiWriter{}
+	for _, sink := range receiver.Sinks {
+		sinkWriter, err := sink.Create(name, headers)
+		if err != nil {
+			writer.Close()
+			return nil, err
+		}
+		writer.writers = append(writer.writers, sinkWriter)
+	}
+	return writer, nil
+}
+
+// multiWriter This is the Writer of a report of a MultiSink
+type multiWriter struct {
+	writers []Writer
+}
+
+// Write adds the row to the report of every sink
+func (receiver *multiWriter) Write(row []any) error {
+	for _, writer := range receiver.writers {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the report of every sink and returns the first error
+func (receiver *multiWriter) Close() error {
+	var first error
+	for _, writer := range receiver.writers {
+		if err := writer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// CSVSink This is a Sink that writes every report to a csv file in a directory
+type CSVSink struct {
+	Path string
+}
+
+// NewCSVSink returns a new CSVSink writing to the given directory
+func NewCSVSink(path string) *CSVSink {
+	return &CSVSink{Path: path}
+}
+
+// Create creates the csv file of the report and writes the headers
+func (receiver *CSVSink) Create(name string, headers []string) (Writer, error) {
+	csvFile, err := os.Create(filepath.Join(receiver.Path, name+".csv"))
+	if err != nil {
+		return nil, err
+	}
+	writer := &csvWriter{file: csvFile, writer: csv.NewWriter(csvFile)}
+	if err := writer.writer.Write(headers); err != nil {
+		csvFile.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+// csvWriter This is the Writer of a single csv file
+type csvWriter struct {
+	file   *os.File
+	writer *csv.Writer
+}
+
+// Write adds a row to the csv file, the nested values are encoded as JSON
+func (receiver *csvWriter) Write(row []any) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		cell, err := FormatCell(value)
+		if err != nil {
+			return err
+		}
+		record[i] = cell
+	}
+	return receiver.writer.Write(record)
+}
+
+// FormatCell returns the text of a value in a csv cell: the lists of strings are comma separated,
+// the nested values are encoded as JSON and nil is empty
+func FormatCell(value any) (string, error) {
+	switch typed := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typed, nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case int:
+		return strconv.Itoa(typed), nil
+	case int64:
+		return strconv.FormatInt(typed, 10), nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), nil
+	case time.Time:
+		return typed.Format(time.RFC3339), nil
+	case []string:
+		return strings.Join(typed, ","), nil
+	}
+	if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Map || reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Pointer {
+		if reflected.IsNil() {
+			return "", nil
+		}
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// Close flushes and closes the csv file
+func (receiver *csvWriter) Close() error {
+	receiver.writer.Flush()
+	if err := receiver.writer.Error(); err != nil {
+		receiver.file.Close()
+		return err
+	}
+	return receiver.file.Close()
+}
+
+// WriteAll creates a report and writes all the rows to it
+func WriteAll(sink Sink, name string, headers []string, rows [][]any) error {
+	timer := time.Now()
+	log.Printf("Writing %d rows to %s", len(rows)+1, name)
+	writer, err := sink.Create(name, headers)
+	if err != nil {
+		return err
+	}
+	for i, row := range rows {
+		PrintProgressBar(i+1, len(rows), "Writing to "+name)
+		if err := writer.Write(row); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	log.Printf("Finished writing %s in %s", name, time.Since(timer))
+	return nil
+}
+
+// PrintProgressBar function that prints a progress bar to the console
+func PrintProgressBar(current, total int, title string) {
+	barLength := 30
+	percent := float64(current) / float64(total)
+	bar := strings.Repeat("=", int(percent*float64(barLength)))
+	fmt.Printf("\r[%s] %.0f%% (%d/%d) %s", bar+strings.Repeat(" ", barLength-int(percent*float64(barLength))), percent*100, current, total, title)
+	if current == total {
+		fmt.Println()
+	}
+}
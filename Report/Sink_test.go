@@ -0,0 +1,94 @@
+package Report_test
+
+import (
+	"database/sql"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatCell(t *testing.T) {
+	var tokens []map[string]string
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{true, "true"},
+		{42, "42"},
+		{int64(7), "7"},
+		{[]string{"a@example.com", "b@example.com"}, "a@example.com,b@example.com"},
+		{tokens, ""},
+		{[]map[string]string{}, "[]"},
+		{map[string]string{"group@example.com": "reader"}, `{"group@example.com":"reader"}`},
+	}
+	for _, test := range tests {
+		got, err := Report.FormatCell(test.value)
+		if err != nil || got != test.want {
+			t.Errorf("FormatCell(%#v) = %q, %v, want %q", test.value, got, err, test.want)
+		}
+	}
+}
+
+func TestNewSinkWritesEveryFormat(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := Report.NewSink([]string{"csv", "jsonl", "sqlite"}, dir)
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+	headers := []string{"email", "member_count", "owners"}
+	rows := [][]any{
+		{"group@example.com", int64(3), []string{"a@example.com", "b@example.com"}},
+		{"empty@example.com", int64(0), []string(nil)},
+	}
+	if err := Report.WriteAll(sink, "groups", headers, rows); err != nil {
+		t.Fatalf("WriteAll: %v", err)
+	}
+	if err := Report.Close(sink); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "groups.csv"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(data), `group@example.com,3,"a@example.com,b@example.com"`) {
+		t.Errorf("groups.csv = %q", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "groups.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := `{"email":"group@example.com","member_count":3,"owners":["a@example.com","b@example.com"]}`
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || lines[0] != want {
+		t.Errorf("groups.jsonl = %q, want first line %s", data, want)
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, Report.SQLiteFileName))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+	var count int64
+	var owners string
+	if err := db.QueryRow(`SELECT member_count, owners FROM groups WHERE email = ?`, "group@example.com").Scan(&count, &owners); err != nil {
+		t.Fatalf("QueryRow: %v", err)
+	}
+	if count != 3 || owners != `["a@example.com","b@example.com"]` {
+		t.Errorf("sqlite row = %d, %s", count, owners)
+	}
+	var empty sql.NullString
+	if err := db.QueryRow(`SELECT owners FROM groups WHERE email = ?`, "empty@example.com").Scan(&empty); err != nil || empty.Valid {
+		t.Errorf("sqlite owners of the empty group = %v, %v, want NULL", empty, err)
+	}
+}
+
+func TestNewSinkRejectsUnknownFormats(t *testing.T) {
+	if _, err := Report.NewSink([]string{"xml"}, t.TempDir()); err == nil {
+		t.Error("NewSink accepted the xml format")
+	}
+}
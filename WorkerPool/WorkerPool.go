@@ -0,0 +1,239 @@
+package WorkerPool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Options This is the struct that configures a run of the worker pool
+type Options struct {
+	// Concurrency is the maximum number of jobs running at the same time, defaults to 1
+	Concurrency int
+	// Progress is called after every completed job with the number of completed jobs and the total, never concurrently
+	Progress func(completed, total int)
+}
+
+// Result This is the outcome of a single job
+type Result[R any] struct {
+	// Index is the position of the job in the jobs slice
+	Index int
+	Value R
+	Err   error
+}
+
+// Run executes the work function over every job and returns the results in the order of the jobs.
+// Once the context is done no new job is started and the jobs that did not run fail with the error of the context.
+func Run[J, R any](ctx context.Context, jobs []J, options Options, work func(ctx context.Context, job J) (R, error)) []Result[R] {
+	results := make([]Result[R], len(jobs))
+	started := make([]bool, len(jobs))
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(jobs) {
+		concurrency = len(jobs)
+	}
+
+	// Feed the job indexes to the workers until the context is done
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+		for i := range jobs {
+			select {
+			case <-ctx.Done():
+				return
+			case indexes <- i:
+			}
+		}
+	}()
+
+	// Every worker writes to its own slots of the results, only the progress is shared
+	progressMutex := &sync.Mutex{}
+	completed := 0
+	wg := &sync.WaitGroup{}
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				started[i] = true
+				value, err := work(ctx, jobs[i])
+				results[i] = Result[R]{Index: i, Value: value, Err: err}
+				if options.Progress != nil {
+					progressMutex.Lock()
+					completed++
+					options.Progress(completed, len(jobs))
+					progressMutex.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Fail the jobs that were never started
+	for i := range results {
+		if !started[i] {
+			results[i] = Result[R]{Index: i, Err: ctx.Err()}
+		}
+	}
+	return results
+}
+
+// Values returns the values of the jobs that succeeded in the order of the jobs
+func Values[R any](results []Result[R]) []R {
+	var values []R
+	for _, result := range results {
+		if result.Err == nil {
+			values = append(values, result.Value)
+		}
+	}
+	return values
+}
+
+// Errors returns the results of the jobs that failed, including the jobs cancelled by the context
+func Errors[R any](results []Result[R]) []Result[R] {
+	var failed []Result[R]
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// LogProgress returns a progress callback that logs every given number of completed jobs and at the end, a zero total is unknown
+func LogProgress(title string, every int) func(completed, total int) {
+	if every < 1 {
+		every = 1
+	}
+	return func(completed, total int) {
+		if total == 0 && completed%every == 0 {
+			log.Printf("<----- %s [%d] ----->", title, completed)
+		} else if completed%every == 0 || completed == total {
+			log.Printf("<----- %s [%d] of [%d] ----->", title, completed, total)
+		}
+	}
+}
+
+// IncompleteError This is the error returned when the context is done before every job completed
+type IncompleteError struct {
+	// Entity is the kind of the jobs, for example "users"
+	Entity string
+	// Missing is the number of jobs cancelled by the context, -1 when it is unknown because the jobs were streamed
+	Missing int
+	Total   int
+	Err     error
+}
+
+// Error returns the number of jobs that were not completed
+func (receiver *IncompleteError) Error() string {
+	if receiver.Missing < 0 {
+		return fmt.Sprintf("only %d %s were collected: %v", receiver.Total, receiver.Entity, receiver.Err)
+	}
+	return fmt.Sprintf("%d of %d %s were not collected: %v", receiver.Missing, receiver.Total, receiver.Entity, receiver.Err)
+}
+
+// Unwrap returns the error of the context
+func (receiver *IncompleteError) Unwrap() error {
+	return receiver.Err
+}
+
+// IsIncomplete returns true if the error is or wraps an IncompleteError
+func IsIncomplete(err error) bool {
+	var incomplete *IncompleteError
+	return errors.As(err, &incomplete)
+}
+
+// Incomplete returns an IncompleteError if jobs of the results were cancelled by the context, nil otherwise
+func Incomplete[R any](ctx context.Context, entity string, results []Result[R]) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	missing := 0
+	for _, result := range results {
+		if errors.Is(result.Err, ctx.Err()) {
+			missing++
+		}
+	}
+	return &IncompleteError{Entity: entity, Missing: missing, Total: len(results), Err: ctx.Err()}
+}
+
+// Stopped returns an IncompleteError if the context is done, for the jobs of a stream whose total is unknown
+func Stopped(ctx context.Context, entity string, collected int) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return &IncompleteError{Entity: entity, Missing: -1, Total: collected, Err: ctx.Err()}
+}
+
+// Stream executes the work function over every job received from the channel, until it is closed or the context is done.
+// The results are sent in the order the jobs complete, their Index is the position of the job in the channel.
+// The results channel is closed after the last job and must be read until then.
+// Once the context is done the jobs left in the channel are read without being started, the results channel is only closed
+// after the jobs channel, so the sender must close it and whatever it wrote before closing it is visible to the reader of the results.
+func Stream[J, R any](ctx context.Context, jobs <-chan J, options Options, work func(ctx context.Context, job J) (R, error)) <-chan Result[R] {
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Number the jobs in the order they are received until the context is done
+	type indexedJob struct {
+		index int
+		job   J
+	}
+	indexed := make(chan indexedJob)
+	go func() {
+		defer close(indexed)
+		// Drain the jobs left once the context is done, the results are closed after the jobs
+		defer func() {
+			for range jobs {
+			}
+		}()
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case job, ok := <-jobs:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case indexed <- indexedJob{i, job}:
+				}
+			}
+		}
+	}()
+
+	// The total is unknown while the jobs are streamed, the progress gets 0
+	results := make(chan Result[R])
+	progressMutex := &sync.Mutex{}
+	completed := 0
+	wg := &sync.WaitGroup{}
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for job := range indexed {
+				value, err := work(ctx, job.job)
+				results <- Result[R]{Index: job.index, Value: value, Err: err}
+				if options.Progress != nil {
+					progressMutex.Lock()
+					completed++
+					options.Progress(completed, 0)
+					progressMutex.Unlock()
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
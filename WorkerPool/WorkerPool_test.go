@@ -0,0 +1,145 @@
+package WorkerPool_test
+
+import (
+	"context"
+	"errors"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/WorkerPool"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunKeepsOrderAndErrors(t *testing.T) {
+	jobs := make([]int, 500)
+	for i := range jobs {
+		jobs[i] = i
+	}
+	var running, maxRunning int32
+	progressCalls := 0
+
+	results := WorkerPool.Run(context.Background(), jobs, WorkerPool.Options{
+		Concurrency: 8,
+		Progress:    func(completed, total int) { progressCalls++ },
+	}, func(ctx context.Context, job int) (int, error) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			observed := atomic.LoadInt32(&maxRunning)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+				break
+			}
+		}
+		time.Sleep(time.Microsecond)
+		atomic.AddInt32(&running, -1)
+		if job%100 == 0 {
+			return 0, errors.New("failed")
+		}
+		return job * 2, nil
+	})
+
+	if len(results) != len(jobs) {
+		t.Fatalf("Run returned %d results, want %d", len(results), len(jobs))
+	}
+	for i, result := range results {
+		if result.Index != i || (result.Err == nil && result.Value != i*2) {
+			t.Fatalf("result %d = %+v, want index %d and value %d", i, result, i, i*2)
+		}
+	}
+	if failed := WorkerPool.Errors(results); len(failed) != 5 {
+		t.Errorf("Errors returned %d results, want 5", len(failed))
+	}
+	if values := WorkerPool.Values(results); len(values) != 495 {
+		t.Errorf("Values returned %d values, want 495", len(values))
+	}
+	if maxRunning > 8 {
+		t.Errorf("%d jobs ran at the same time, want at most 8", maxRunning)
+	}
+	if progressCalls != len(jobs) {
+		t.Errorf("Progress was called %d times, want %d", progressCalls, len(jobs))
+	}
+}
+
+func TestRunWithoutJobs(t *testing.T) {
+	results := WorkerPool.Run(context.Background(), []string{}, WorkerPool.Options{Concurrency: 4}, func(ctx context.Context, job string) (string, error) {
+		return job, nil
+	})
+	if len(results) != 0 {
+		t.Errorf("Run returned %d results, want 0", len(results))
+	}
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	jobs := make([]int, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := WorkerPool.Run(ctx, jobs, WorkerPool.Options{Concurrency: 2}, func(ctx context.Context, job int) (int, error) {
+		cancel()
+		return job, nil
+	})
+
+	cancelled := 0
+	for _, result := range results {
+		if errors.Is(result.Err, context.Canceled) {
+			cancelled++
+		}
+	}
+	if cancelled == 0 || cancelled > len(jobs)-1 {
+		t.Errorf("%d jobs were cancelled, want every job but the ones already started", cancelled)
+	}
+}
+
+func TestStream(t *testing.T) {
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := 0; i < 100; i++ {
+			jobs <- i
+		}
+	}()
+
+	seen := make(map[int]bool)
+	for result := range WorkerPool.Stream(context.Background(), jobs, WorkerPool.Options{Concurrency: 4}, func(ctx context.Context, job int) (int, error) {
+		return job * 2, nil
+	}) {
+		if result.Err != nil || result.Value != result.Index*2 || seen[result.Index] {
+			t.Fatalf("result = %+v, want a new index and twice its value", result)
+		}
+		seen[result.Index] = true
+	}
+	if len(seen) != 100 {
+		t.Errorf("Stream returned %d results, want 100", len(seen))
+	}
+}
+
+func TestStreamStopsWhenCancelled(t *testing.T) {
+	// The sender closes the jobs once the context is done, the stream ends after it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- i:
+			}
+		}
+	}()
+
+	completed := 0
+	for range WorkerPool.Stream(ctx, jobs, WorkerPool.Options{Concurrency: 2}, func(ctx context.Context, job int) (int, error) {
+		if job == 10 {
+			cancel()
+		}
+		return job, nil
+	}) {
+		completed++
+	}
+	if completed < 11 {
+		t.Errorf("Stream returned %d results, want at least the 11 jobs started before the cancellation", completed)
+	}
+	if err := WorkerPool.Stopped(ctx, "jobs", completed); !WorkerPool.IsIncomplete(err) {
+		t.Errorf("Stopped = %v, want an IncompleteError", err)
+	}
+}
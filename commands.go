@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Config"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Exit codes returned by the application
+const (
+	ExitOK          = 0 // The command completed successfully
+	ExitFailure     = 1 // The command started but failed while running
+	ExitUsage       = 2 // The command line could not be parsed
+	ExitInterrupted = 3 // The command was interrupted by a signal or the timeout, the reports are partial
+	ExitErrorRate   = 4 // The audits completed but skipped more entities than allowed by -max_error_rate
+)
+
+// Command This is a struct that describes a subcommand of the application
+type Command struct {
+	Name        string
+	Description string
+	// Auditors are the audits run by the command
+	Auditors []Audit.Auditor
+	// Configured is set when the audits are selected by the configuration rather than by the command
+	Configured bool
+}
+
+// Commands returns a command for every registered audit followed by the "all" and "run" commands
+func Commands() []*Command {
+	var commands []*Command
+	for _, auditor := range Audit.All() {
+		commands = append(commands, &Command{
+			Name:        auditor.Name(),
+			Description: auditor.Description(),
+			Auditors:    []Audit.Auditor{auditor}})
+	}
+	return append(commands, &Command{
+		Name:        "all",
+		Description: "Run every audit above",
+		Auditors:    Audit.All()}, &Command{
+		Name:        "run",
+		Description: "Run the audits selected by -audits or the config file, every audit when none is selected",
+		Auditors:    Audit.All(),
+		Configured:  true})
+}
+
+// findCommand returns the command with the given name or nil
+func findCommand(name string) *Command {
+	for _, command := range Commands() {
+		if command.Name == name {
+			return command
+		}
+	}
+	return nil
+}
+
+// newCommandFlagSet creates the flag set for a command and binds it to the configuration, the current values are the defaults
+func newCommandFlagSet(command *Command, config *Config.Config, output io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(command.Name, flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.String("config", "", "string: YAML or JSON config file, overridden by the "+Config.EnvPrefix+"* environment variables and the flags")
+	flags.StringVar(&config.Credentials.AccessToken, "access_token", config.Credentials.AccessToken, "string: Access token")
+	flags.StringVar(&config.Credentials.RefreshToken, "refresh_token", config.Credentials.RefreshToken, "string: Refresh token")
+	flags.StringVar(&config.CustomerID, "customer_id", config.CustomerID, "string: Customer ID")
+	flags.Var(Config.ListFlag{List: &config.Domains}, "domains", "list: Comma separated domains of the organization, members of other domains are external")
+	flags.StringVar(&config.OrgUnit, "org_unit", config.OrgUnit, "string: Organizational unit path whose subtree the user audits are restricted to, like /Engineering")
+	flags.Var(Config.IntListFlag{List: &config.Inactivity.ThresholdDays}, "inactive_days", "list: Comma separated numbers of days without a login after which a user is inactive (default 30,90,180)")
+	flags.IntVar(&config.Inactivity.NewAccountDays, "new_account_days", config.Inactivity.NewAccountDays, "int: Age in days under which an account that never logged in is new rather than dormant (default 14)")
+	flags.StringVar(&config.Output.Path, "output", config.Output.Path, "string: Local directory the reports are written to")
+	flags.Var(Config.ListFlag{List: &config.Output.Formats}, "formats", "list: Comma separated formats of the reports ("+strings.Join(Report.Formats(), ", ")+")")
+	flags.StringVar(&config.Upload.DriveFolder, "drive_folder", config.Upload.DriveFolder, "string: Google Drive folder id the zipped reports are uploaded to")
+	flags.Var(invertedBool{&config.Upload.Enabled}, "no_upload", "bool: Keep the reports locally and skip the Google Drive upload")
+	flags.IntVar(&config.Concurrency.Audits, "concurrency", config.Concurrency.Audits, "int: Number of entities an audit processes at the same time")
+	flags.BoolVar(&config.Fields.All, "all_fields", config.Fields.All, "bool: Request every field of the Google API responses instead of the fields read by the audits")
+	flags.Float64Var(&config.MaxErrorRate, "max_error_rate", config.MaxErrorRate, "float: Highest rate of entities skipped because of an error before the run fails, listed in errors.csv")
+	flags.StringVar(&config.Resume, "resume", "", "string: Reports directory of an interrupted run to continue, the reports are written to it")
+	flags.DurationVar(&config.Timeout, "timeout", config.Timeout, "duration: Stop the audits and write partial reports after this time, for example 2h30m (0 means no timeout)")
+	if command.Configured {
+		flags.Var(Config.ListFlag{List: &config.Audits}, "audits", "list: Comma separated audits to run")
+	}
+	if Audit.NeedsDelegationKey(command.Auditors...) {
+		flags.StringVar(&config.Credentials.DelegationKeyPath, "key_path", config.Credentials.DelegationKeyPath, "string: Delegation Key Path")
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(output, "Usage: %s %s [flags]\n\n%s\n\nFlags:\n", applicationName(), command.Name, command.Description)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseCommandLine reads the configuration of a command from the config file, the environment and the flags, and validates it
+func parseCommandLine(command *Command, args []string, output io.Writer) (*Config.Config, error) {
+	config := Config.Default()
+	if path := configPath(args); path != "" {
+		if err := config.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := config.LoadEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	// The flags override the file and the environment
+	flags := newCommandFlagSet(command, config, output)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	if command.Configured {
+		command.Auditors = config.Auditors()
+	} else {
+		config.Audits = nil
+	}
+	if config.Resume != "" {
+		if len(config.Tenants) > 0 {
+			if err := resumableTenants(config); err != nil {
+				return nil, err
+			}
+		} else if !Checkpoint.Exists(config.Resume) {
+			return nil, fmt.Errorf("no %s to resume in %s", Checkpoint.FileName, config.Resume)
+		}
+		// The resumed run writes to the directory of the previous run
+		config.Output.Path = config.Resume
+	}
+	if err := config.Validate(command.Auditors); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %v", err)
+	}
+	return config, nil
+}
+
+// configPath returns the value of the -config flag, which is read before the other flags so that they override the file
+func configPath(args []string) string {
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// invertedBool This is a boolean flag setting the opposite value, for example -no_upload
+type invertedBool struct {
+	value *bool
+}
+
+// String returns the value of the flag
+func (receiver invertedBool) String() string {
+	if receiver.value == nil {
+		return "false"
+	}
+	return strconv.FormatBool(!*receiver.value)
+}
+
+// Set stores the opposite of the value
+func (receiver invertedBool) Set(value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*receiver.value = !parsed
+	return nil
+}
+
+// IsBoolFlag allows the flag without a value
+func (receiver invertedBool) IsBoolFlag() bool {
+	return true
+}
+
+// applicationName returns the name the application was invoked with
+func applicationName() string {
+	name := os.Args[0]
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// printUsage prints the list of the available commands
+func printUsage(output io.Writer) {
+	fmt.Fprintf(output, "Google Assessment Tool %s\n\nUsage: %s <command> [flags]\n\nCommands:\n", VERSION, applicationName())
+	fmt.Fprintf(output, "  %-15s %s\n", "auth", "Generate the access and refresh tokens used by the other commands")
+	for _, command := range Commands() {
+		fmt.Fprintf(output, "  %-15s %s\n", command.Name, command.Description)
+	}
+	fmt.Fprintf(output, "  %-15s %s\n", "help", "Show this help, or the flags of a command with \"help <command>\"")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Audit"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Checkpoint"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Config"
+	"github.com/MetaPhase-Consulting/gsa-google-assessment-tool/Report"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runTenants runs the audits of the command on every tenant of the configuration one after the other.
+// The reports of a tenant are written to a folder named after it, the combined summary of the tenants to the output path.
+// A tenant failing does not stop the next ones, once the context is done the remaining tenants are not started.
+func runTenants(ctx context.Context, command *Command, config *Config.Config) int {
+	reportsPath := config.Output.Path
+	combined := &Audit.CombinedSummary{Started: time.Now()}
+	var exitCodes []int
+	for i, tenant := range config.Tenants {
+		tenantConfig := config.ForTenant(tenant)
+		tenantPath := tenantConfig.Output.Path
+		if ctx.Err() != nil {
+			combined.Tenants = append(combined.Tenants, Audit.NewTenantSummary(tenant.Name, tenantConfig.CustomerID, tenantPath, nil, nil))
+			continue
+		}
+		log.Printf("Assessing tenant %s (%d of %d)...", tenant.Name, i+1, len(config.Tenants))
+
+		// The tenants not started by the run to resume start from scratch
+		if tenantConfig.Resume != "" && !Checkpoint.Exists(tenantConfig.Resume) {
+			tenantConfig.Resume = ""
+		}
+		if err := os.MkdirAll(tenantPath, os.ModePerm); err != nil {
+			log.Println(err.Error())
+			combined.Tenants = append(combined.Tenants, Audit.NewTenantSummary(tenant.Name, tenantConfig.CustomerID, tenantPath, nil, err))
+			exitCodes = append(exitCodes, ExitFailure)
+			continue
+		}
+		summary, exitCode, err := runCustomer(ctx, command, tenantConfig, reportsPath)
+		if err == nil && summary == nil && exitCode != ExitOK {
+			err = errors.New("the audits did not start")
+		}
+		combined.Tenants = append(combined.Tenants, Audit.NewTenantSummary(tenant.Name, tenantConfig.CustomerID, tenantPath, summary, err))
+		exitCodes = append(exitCodes, exitCode)
+		log.Printf("Tenant %s: %s", tenant.Name, combined.Tenants[len(combined.Tenants)-1].Status)
+	}
+	combined.Finished = time.Now()
+	if err := ctx.Err(); err != nil {
+		combined.Interrupted = true
+		combined.Reason = context.Cause(ctx).Error()
+	}
+
+	// Write the combined summary next to the folders of the tenants
+	exitCode := combineExitCodes(exitCodes)
+	if err := writeCombinedSummary(combined, config.Output.Formats, reportsPath); err != nil {
+		log.Println(err.Error())
+		if exitCode != ExitInterrupted {
+			exitCode = ExitFailure
+		}
+	}
+	if incomplete := combined.Incomplete(); len(incomplete) > 0 {
+		log.Printf("%d of %d tenants were not fully assessed, see %s:", len(incomplete), len(combined.Tenants), filepath.Join(reportsPath, "summary.json"))
+	}
+	for _, tenant := range combined.Incomplete() {
+		log.Printf("  %s: %s", tenant.Tenant, tenant.Status)
+	}
+	if combined.Interrupted {
+		log.Printf("%s was interrupted (%s), continue the run with -resume %s", command.Name, combined.Reason, reportsPath)
+	}
+	return exitCode
+}
+
+// writeCombinedSummary writes summary.json and the tenantsSummary and tenantsAudits reports to the reports directory
+func writeCombinedSummary(combined *Audit.CombinedSummary, formats []string, reportsPath string) error {
+	if err := combined.Write(reportsPath); err != nil {
+		return err
+	}
+	sink, err := Report.NewSink(formats, reportsPath)
+	if err != nil {
+		return err
+	}
+	if err := combined.WriteReports(sink); err != nil {
+		Report.Close(sink)
+		return err
+	}
+	return Report.Close(sink)
+}
+
+// combineExitCodes returns the exit code of a run from the exit codes of its tenants:
+// interrupted before failed, failed before an error rate too high, and ok when every tenant is ok
+func combineExitCodes(exitCodes []int) int {
+	combined := ExitOK
+	for _, precedence := range []int{ExitErrorRate, ExitFailure, ExitInterrupted} {
+		for _, exitCode := range exitCodes {
+			if exitCode == precedence {
+				combined = precedence
+			}
+		}
+	}
+	return combined
+}
+
+// resumableTenants returns an error unless the directory of the run to resume holds the checkpoint of at least one tenant
+func resumableTenants(config *Config.Config) error {
+	for _, tenant := range config.Tenants {
+		if Checkpoint.Exists(config.ForTenant(tenant).Resume) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no %s to resume in the tenant folders of %s", Checkpoint.FileName, config.Resume)
+}